@@ -0,0 +1,191 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/sekiguchi-nagisa/guniset/set"
+)
+
+type CodeGenLang int8
+
+const (
+	LangNone CodeGenLang = iota // only print entries (`{ 0x0000, 0x0000 },`)
+	LangC
+	LangRust
+	LangGo
+	LangJava
+	LangPython
+	LangJavaScript
+	LangTypeScript
+)
+
+var strToCodeGenLang = map[string]CodeGenLang{
+	"none":       LangNone,
+	"c":          LangC,
+	"rust":       LangRust,
+	"go":         LangGo,
+	"java":       LangJava,
+	"python":     LangPython,
+	"javascript": LangJavaScript,
+	"js":         LangJavaScript,
+	"typescript": LangTypeScript,
+	"ts":         LangTypeScript,
+}
+
+// CodeGenOption options for table code generation
+type CodeGenOption struct {
+	Lang CodeGenLang
+	Name string // table name. if empty, use language specific default name
+	Decl string // table declaration (opening part). `{name}` is replaced with table name
+}
+
+type codeGenTemplate struct {
+	name  string // default table name
+	decl  string // default declaration
+	entry string // format of each range entry
+	close string
+}
+
+var codeGenTemplates = map[CodeGenLang]codeGenTemplate{
+	LangC: {
+		name:  "table",
+		decl:  "static const struct { unsigned int first; unsigned int last; } {name}[] = {",
+		entry: "    { 0x%04X, 0x%04X },\n",
+		close: "};",
+	},
+	LangRust: {
+		name:  "TABLE",
+		decl:  "pub static {name}: &[(char, char)] = &[",
+		entry: "    ('\\u{%04X}', '\\u{%04X}'),\n",
+		close: "];",
+	},
+	LangGo: {
+		name:  "table",
+		decl:  "var {name} = &unicode.RangeTable{",
+		close: "}",
+	},
+	LangJava: {
+		name:  "TABLE",
+		decl:  "static final int[][] {name} = {",
+		entry: "    { 0x%04X, 0x%04X },\n",
+		close: "};",
+	},
+	LangPython: {
+		name:  "TABLE",
+		decl:  "{name} = [",
+		entry: "    (0x%04X, 0x%04X),\n",
+		close: "]",
+	},
+	LangJavaScript: {
+		name:  "TABLE",
+		decl:  "export const {name} = [",
+		entry: "    [0x%04X, 0x%04X],\n",
+		close: "];",
+	},
+	LangTypeScript: {
+		name:  "TABLE",
+		decl:  "export const {name}: ReadonlyArray<readonly [number, number]> = [",
+		entry: "    [0x%04X, 0x%04X],\n",
+		close: "];",
+	},
+}
+
+func (o *CodeGenOption) resolveDecl(t *codeGenTemplate) string {
+	name := o.Name
+	if name == "" {
+		name = t.name
+	}
+	decl := o.Decl
+	if decl == "" {
+		decl = t.decl
+	}
+	return strings.ReplaceAll(decl, "{name}", name)
+}
+
+var surrogateRange = set.RuneRange{First: 0xD800, Last: 0xDFFF}
+
+func printGoRangeTable(uniSet *set.UniSet, writer io.Writer) error {
+	var r16 []set.RuneRange
+	var r32 []set.RuneRange
+	for runeRange := range uniSet.Range {
+		if set.IsBmpRune(runeRange.Last) {
+			r16 = append(r16, runeRange)
+		} else if set.IsBmpRune(runeRange.First) { // split into Range16 and Range32
+			r16 = append(r16, set.RuneRange{First: runeRange.First, Last: 0xFFFF})
+			r32 = append(r32, set.RuneRange{First: 0x10000, Last: runeRange.Last})
+		} else {
+			r32 = append(r32, runeRange)
+		}
+	}
+	latinOffset := 0
+	for _, runeRange := range r16 {
+		if runeRange.Last <= 0xFF { // unicode.MaxLatin1
+			latinOffset++
+		}
+	}
+
+	sb := strings.Builder{}
+	if len(r16) > 0 {
+		sb.WriteString("\tR16: []unicode.Range16{\n")
+		for _, runeRange := range r16 {
+			sb.WriteString(fmt.Sprintf("\t\t{Lo: 0x%04X, Hi: 0x%04X, Stride: 1},\n", runeRange.First, runeRange.Last))
+		}
+		sb.WriteString("\t},\n")
+	}
+	if len(r32) > 0 {
+		sb.WriteString("\tR32: []unicode.Range32{\n")
+		for _, runeRange := range r32 {
+			sb.WriteString(fmt.Sprintf("\t\t{Lo: 0x%04X, Hi: 0x%04X, Stride: 1},\n", runeRange.First, runeRange.Last))
+		}
+		sb.WriteString("\t},\n")
+	}
+	if latinOffset > 0 {
+		sb.WriteString(fmt.Sprintf("\tLatinOffset: %d,\n", latinOffset))
+	}
+	_, err := io.WriteString(writer, sb.String())
+	return err
+}
+
+func GenerateUniSetCode(uniSet *set.UniSet, writer io.Writer, option *CodeGenOption) error {
+	if option.Lang == LangNone {
+		return PrintUniSet(uniSet, writer)
+	}
+	t, ok := codeGenTemplates[option.Lang]
+	if !ok {
+		return fmt.Errorf("unsupported language: %d", option.Lang)
+	}
+	if option.Lang == LangRust { // Rust char cannot represent surrogate code points
+		tmp := uniSet.Copy()
+		tmp.RemoveRange(surrogateRange)
+		uniSet = &tmp
+	}
+	_, err := fmt.Fprintln(writer, option.resolveDecl(&t))
+	if err != nil {
+		return err
+	}
+	if option.Lang == LangGo {
+		err = printGoRangeTable(uniSet, writer)
+		if err != nil {
+			return err
+		}
+	} else {
+		for runeRange := range uniSet.Range {
+			_, err = fmt.Fprintf(writer, t.entry, runeRange.First, runeRange.Last)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	_, err = fmt.Fprintln(writer, t.close)
+	return err
+}
+
+func (g *GUniSet) RunAndGenerate(filterOp SetFilterOp, option *CodeGenOption) error {
+	uniSet, err := g.Run(filterOp)
+	if err != nil {
+		return err
+	}
+	return GenerateUniSetCode(uniSet, g.Writer, option)
+}
@@ -12,6 +12,7 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/sekiguchi-nagisa/guniset/op"
 	"github.com/sekiguchi-nagisa/guniset/set"
@@ -35,6 +36,10 @@ type GUniSet struct {
 	UnicodeData  *op.UnicodeData
 	Writer       io.Writer // for generated Unicode set string
 	SetOperation string
+	// if not empty, read set operation from file instead of SetOperation
+	SetOperationFile string
+	Strict           bool            // if true, disable loose matching of property names and values
+	ctx              *op.EvalContext // if not nil, used instead of loading Unicode database
 }
 
 func NewGUniSetFromDir(unicodeDir string, writer io.Writer, setOperation string) (*GUniSet, error) {
@@ -45,6 +50,32 @@ func NewGUniSetFromDir(unicodeDir string, writer io.Writer, setOperation string)
 	}, nil
 }
 
+// data sources of Unicode database
+const (
+	SourceAuto   = "auto"   // use UCDXML if exists, otherwise use UCD text files
+	SourceText   = "text"   // UCD text files
+	SourceUCDXML = "ucdxml" // UCDXML flat file (ucd.all.flat.xml or zip)
+)
+
+// SelectSource select data source of Unicode database in unicodeDir
+func (g *GUniSet) SelectSource(unicodeDir string, source string) error {
+	switch source {
+	case SourceAuto:
+		g.UnicodeData.UCDXML = op.FindUCDXML(unicodeDir)
+	case SourceText:
+		g.UnicodeData.UCDXML = ""
+	case SourceUCDXML:
+		g.UnicodeData.UCDXML = op.FindUCDXML(unicodeDir)
+		if g.UnicodeData.UCDXML == "" {
+			return fmt.Errorf("UCDXML file (%s) is not found in %s",
+				strings.Join(op.UCDXMLFiles, ", "), unicodeDir)
+		}
+	default:
+		return fmt.Errorf("unknown source %q", source)
+	}
+	return nil
+}
+
 func PrintUniSet(uniSet *set.UniSet, writer io.Writer) error {
 	for runeRange := range uniSet.Range {
 		_, err := fmt.Fprintf(writer, "{ 0x%04X, 0x%04X },\n", runeRange.First, runeRange.Last)
@@ -56,6 +87,9 @@ func PrintUniSet(uniSet *set.UniSet, writer io.Writer) error {
 }
 
 func (g *GUniSet) prepare() (*op.EvalContext, error) {
+	if g.ctx != nil {
+		return g.ctx, nil
+	}
 	return op.NewEvalContext(g.UnicodeData)
 }
 
@@ -64,7 +98,13 @@ func (g *GUniSet) Run(filterOp SetFilterOp) (*set.UniSet, error) {
 	if err != nil {
 		return nil, err
 	}
-	node, err := op.NewParser(ctx.AliasMapRecord, &ctx.DefRecord).Run([]byte(g.SetOperation))
+	parser := op.NewParser(ctx.AliasMapRecord, &ctx.DefRecord).SetStrict(g.Strict)
+	var node op.Node
+	if g.SetOperationFile != "" {
+		node, err = parser.RunFile(g.SetOperationFile)
+	} else {
+		node, err = parser.Run([]byte(g.SetOperation))
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -72,13 +112,9 @@ func (g *GUniSet) Run(filterOp SetFilterOp) (*set.UniSet, error) {
 	switch filterOp {
 	case SetPrintAll: // do nothing
 	case SetPrintBMP:
-		uniSet.Filter(func(r rune) bool { // only allow bmp rune (remove non-bmp)
-			return set.IsBmpRune(r)
-		})
+		uniSet.RemoveRange(set.RuneRange{First: 0x10000, Last: utf8.MaxRune}) // only allow bmp rune (remove non-bmp)
 	case SetPrintNonBMP:
-		uniSet.Filter(func(r rune) bool { // only allow non-bmp rune (remove bmp)
-			return set.IsSupplementaryRune(r)
-		})
+		uniSet.RemoveRange(set.RuneRange{First: 0, Last: 0xFFFF}) // only allow non-bmp rune (remove bmp)
 	}
 	return &uniSet, nil
 }
@@ -188,10 +224,11 @@ func formatScriptX(def *op.ScriptDef, scx []op.Script) string {
 	return builder.String()
 }
 
-func formatEmoji(def *op.PropertyDef[op.Emoji], emoji []op.Emoji) string {
+// formatPropertyList format multiple properties of code point (such as emoji, idty)
+func formatPropertyList[T ~int](def *op.PropertyDef[T], properties []T) string {
 	builder := strings.Builder{}
 	builder.WriteString("[")
-	for i, s := range emoji {
+	for i, s := range properties {
 		if i > 0 {
 			builder.WriteString(", ")
 		}
@@ -273,7 +310,132 @@ func (g *GUniSet) Query(asString bool) error {
 			sbp = ctx.DefRecord.SentenceBreakPropDef.Format(s)
 		}
 	}
+	lbUnknown, _ := ctx.DefRecord.LineBreakDef.Parse(op.LineBreakUnknown)
+	lb := ctx.DefRecord.LineBreakDef.FormatWithAlias(lbUnknown, ctx.AliasMapRecord.LineBreak())
+	for s, uniSet := range ctx.LineBreakMap {
+		if uniSet.Find(r) {
+			lb = ctx.DefRecord.LineBreakDef.FormatWithAlias(s, ctx.AliasMapRecord.LineBreak())
+			break
+		}
+	}
+	insc := ""
+	for s, uniSet := range ctx.IndicSyllabicCategoryMap {
+		if uniSet.Find(r) {
+			insc = ctx.DefRecord.IndicSyllabicCategoryDef.Format(s)
+			break
+		}
+	}
+	inpc := ""
+	for s, uniSet := range ctx.IndicPositionalCategoryMap {
+		if uniSet.Find(r) {
+			inpc = ctx.DefRecord.IndicPositionalCategoryDef.Format(s)
+			break
+		}
+	}
+	vo := ""
+	for s, uniSet := range ctx.VerticalOrientationMap {
+		if uniSet.Find(r) {
+			vo = ctx.DefRecord.VerticalOrientationDef.FormatWithAlias(s, ctx.AliasMapRecord.VerticalOrientation())
+			break
+		}
+	}
+	hst := ""
+	for s, uniSet := range ctx.HangulSyllableTypeMap {
+		if uniSet.Find(r) {
+			hst = ctx.DefRecord.HangulSyllableTypeDef.FormatWithAlias(s, ctx.AliasMapRecord.HangulSyllableType())
+			break
+		}
+	}
+	name, _ := ctx.CharNames.Lookup(r)
+	bc := ""
+	for s, uniSet := range ctx.BidiClassMap {
+		if uniSet.Find(r) {
+			bc = ctx.DefRecord.BidiClassDef.FormatWithAlias(s, ctx.AliasMapRecord.BidiClass())
+			break
+		}
+	}
+	ccc := ""
+	for s, uniSet := range ctx.CombiningClassMap {
+		if uniSet.Find(r) {
+			ccc = ctx.DefRecord.CombiningClassDef.FormatWithAlias(s, ctx.AliasMapRecord.CombiningClass())
+			break
+		}
+	}
+	bpt := ""
+	for s, uniSet := range ctx.BidiPairedBracketTypeMap {
+		if uniSet.Find(r) {
+			bpt = ctx.DefRecord.BidiPairedBracketTypeDef.FormatWithAlias(s, ctx.AliasMapRecord.BidiPairedBracketType())
+			break
+		}
+	}
+	bmg := ""
+	if mirrored, ok := ctx.BidiMirroringMap[r]; ok {
+		bmg = fmt.Sprintf("U+%04X", mirrored)
+	}
+	dt := ""
+	for s, uniSet := range ctx.DecompositionTypeMap {
+		if uniSet.Find(r) {
+			dt = ctx.DefRecord.DecompositionTypeDef.FormatWithAlias(s, ctx.AliasMapRecord.DecompositionType())
+			break
+		}
+	}
+	age := ""
+	for s, uniSet := range ctx.AgeMap {
+		if uniSet.Find(r) {
+			age = ctx.DefRecord.AgeDef.FormatWithAlias(s, ctx.AliasMapRecord.Age())
+			break
+		}
+	}
+	blk := ""
+	for s, uniSet := range ctx.BlockMap {
+		if uniSet.Find(r) {
+			blk = ctx.DefRecord.BlockDef.FormatWithAlias(s, ctx.AliasMapRecord.Block())
+			break
+		}
+	}
+	jt := ""
+	for s, uniSet := range ctx.JoiningTypeMap {
+		if uniSet.Find(r) {
+			jt = ctx.DefRecord.JoiningTypeDef.FormatWithAlias(s, ctx.AliasMapRecord.JoiningType())
+			break
+		}
+	}
+	jg := ""
+	for s, uniSet := range ctx.JoiningGroupMap {
+		if uniSet.Find(r) {
+			jg = ctx.DefRecord.JoiningGroupDef.FormatWithAlias(s, ctx.AliasMapRecord.JoiningGroup())
+			break
+		}
+	}
+	nt := ""
+	for s, uniSet := range ctx.NumericTypeMap {
+		if uniSet.Find(r) {
+			nt = ctx.DefRecord.NumericTypeDef.FormatWithAlias(s, ctx.AliasMapRecord.NumericType())
+			break
+		}
+	}
+	nv := ""
+	for s, uniSet := range ctx.NumericValueMap {
+		if uniSet.Find(r) {
+			nv = ctx.DefRecord.NumericValueDef.Format(s)
+			break
+		}
+	}
+	idst := ""
+	for s, uniSet := range ctx.IdentifierStatusMap {
+		if uniSet.Find(r) {
+			idst = ctx.DefRecord.IdentifierStatusDef.Format(s)
+			break
+		}
+	}
+	var idty []op.IdentifierType
+	for s := range ctx.DefRecord.IdentifierTypeDef.EachProperty {
+		if m, ok := ctx.IdentifierTypeMap[s]; ok && m.Find(r) {
+			idty = append(idty, s) // may have multiple property
+		}
+	}
 	_, err = fmt.Fprintf(g.Writer, "CodePoint: U+%04X\n"+
+		"Name: %s\n"+
 		"GeneralCategory: %s\n"+
 		"EastAsianWidth: %s\n"+
 		"Script: %s\n"+
@@ -281,16 +443,73 @@ func (g *GUniSet) Query(asString bool) error {
 		"Emoji: %s\n"+
 		"GraphemeBreak: %s\n"+
 		"WordBreak: %s\n"+
-		"SentenceBreak: %s\n", r,
+		"SentenceBreak: %s\n"+
+		"LineBreak: %s\n"+
+		"IndicSyllabicCategory: %s\n"+
+		"IndicPositionalCategory: %s\n"+
+		"VerticalOrientation: %s\n"+
+		"HangulSyllableType: %s\n"+
+		"BidiClass: %s\n"+
+		"BidiMirroringGlyph: %s\n"+
+		"BidiPairedBracketType: %s\n"+
+		"CombiningClass: %s\n"+
+		"DecompositionType: %s\n"+
+		"Age: %s\n"+
+		"Block: %s\n"+
+		"JoiningType: %s\n"+
+		"JoiningGroup: %s\n"+
+		"NumericType: %s\n"+
+		"NumericValue: %s\n"+
+		"IdentifierStatus: %s\n"+
+		"IdentifierType: %s\n", r, name,
 		cat.Format(ctx.AliasMapRecord.Category()),
 		eaw.Format(ctx.AliasMapRecord.Eaw()),
 		ctx.DefRecord.ScriptDef.Format(sc, ctx.AliasMapRecord.Script()),
 		formatScriptX(ctx.DefRecord.ScriptDef, scx),
-		formatEmoji(ctx.DefRecord.EmojiDef, emoji),
-		gbp, wbp, sbp)
+		formatPropertyList(ctx.DefRecord.EmojiDef, emoji),
+		gbp, wbp, sbp, lb, insc, inpc, vo, hst, bc, bmg, bpt, ccc, dt, age, blk,
+		jt, jg, nt, nv, idst, formatPropertyList(ctx.DefRecord.IdentifierTypeDef, idty))
 	return err
 }
 
+func formatCodePoints(runes []rune) string {
+	ss := make([]string, 0, len(runes))
+	for _, r := range runes {
+		ss = append(ss, fmt.Sprintf("U+%04X", r))
+	}
+	return strings.Join(ss, " ")
+}
+
+// Confusables print skeleton (UTS #39) of input string and source characters confusable with it
+func (g *GUniSet) Confusables() error {
+	input := []rune(g.SetOperation)
+	if len(input) == 0 {
+		return errors.New("invalid string. must not be empty")
+	}
+	ctx, err := g.prepare()
+	if err != nil {
+		return err
+	}
+	ignorables := ctx.DefaultIgnorables()
+	skeleton := ctx.Confusables.Skeleton(input, ctx.Normalizer, ignorables)
+	_, err = fmt.Fprintf(g.Writer, "Input: %s (%s)\nSkeleton: %s (%s)\nSources:\n",
+		string(input), formatCodePoints(input), string(skeleton), formatCodePoints(skeleton))
+	if err != nil {
+		return err
+	}
+	for _, r := range ctx.Confusables.Sources(skeleton, ctx.Normalizer, ignorables) {
+		line := fmt.Sprintf("  U+%04X %s", r, string(r))
+		if name, ok := ctx.CharNames.Lookup(r); ok {
+			line += " " + name
+		}
+		_, err = fmt.Fprintln(g.Writer, line)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (g *GUniSet) Info() error {
 	ctx, err := g.prepare()
 	if err != nil {
@@ -364,6 +583,91 @@ func (g *GUniSet) EnumerateProperty() error {
 			_, _ = fmt.Fprintln(g.Writer, ctx.DefRecord.SentenceBreakPropDef.Format(prop))
 		}
 		return nil
+	case op.IsIndicSyllabicCategoryPrefix(g.SetOperation):
+		for prop := range ctx.DefRecord.IndicSyllabicCategoryDef.EachProperty {
+			_, _ = fmt.Fprintln(g.Writer, ctx.DefRecord.IndicSyllabicCategoryDef.Format(prop))
+		}
+		return nil
+	case op.IsIndicPositionalCategoryPrefix(g.SetOperation):
+		for prop := range ctx.DefRecord.IndicPositionalCategoryDef.EachProperty {
+			_, _ = fmt.Fprintln(g.Writer, ctx.DefRecord.IndicPositionalCategoryDef.Format(prop))
+		}
+		return nil
+	case op.IsVerticalOrientationPrefix(g.SetOperation):
+		for prop := range ctx.DefRecord.VerticalOrientationDef.EachProperty {
+			_, _ = fmt.Fprintln(g.Writer, ctx.DefRecord.VerticalOrientationDef.FormatWithAlias(prop, ctx.AliasMapRecord.VerticalOrientation()))
+		}
+		return nil
+	case op.IsHangulSyllableTypePrefix(g.SetOperation):
+		for prop := range ctx.DefRecord.HangulSyllableTypeDef.EachProperty {
+			_, _ = fmt.Fprintln(g.Writer, ctx.DefRecord.HangulSyllableTypeDef.FormatWithAlias(prop, ctx.AliasMapRecord.HangulSyllableType()))
+		}
+		return nil
+	case op.IsLineBreakPrefix(g.SetOperation):
+		for prop := range ctx.DefRecord.LineBreakDef.EachProperty {
+			_, _ = fmt.Fprintln(g.Writer, ctx.DefRecord.LineBreakDef.FormatWithAlias(prop, ctx.AliasMapRecord.LineBreak()))
+		}
+		return nil
+	case op.IsBidiClassPrefix(g.SetOperation):
+		for prop := range ctx.DefRecord.BidiClassDef.EachProperty {
+			_, _ = fmt.Fprintln(g.Writer, ctx.DefRecord.BidiClassDef.FormatWithAlias(prop, ctx.AliasMapRecord.BidiClass()))
+		}
+		return nil
+	case op.IsBidiPairedBracketTypePrefix(g.SetOperation):
+		for prop := range ctx.DefRecord.BidiPairedBracketTypeDef.EachProperty {
+			_, _ = fmt.Fprintln(g.Writer, ctx.DefRecord.BidiPairedBracketTypeDef.FormatWithAlias(prop, ctx.AliasMapRecord.BidiPairedBracketType()))
+		}
+		return nil
+	case op.IsCombiningClassPrefix(g.SetOperation):
+		for prop := range ctx.DefRecord.CombiningClassDef.EachProperty {
+			_, _ = fmt.Fprintln(g.Writer, ctx.DefRecord.CombiningClassDef.FormatWithAlias(prop, ctx.AliasMapRecord.CombiningClass()))
+		}
+		return nil
+	case op.IsDecompositionTypePrefix(g.SetOperation):
+		for prop := range ctx.DefRecord.DecompositionTypeDef.EachProperty {
+			_, _ = fmt.Fprintln(g.Writer, ctx.DefRecord.DecompositionTypeDef.FormatWithAlias(prop, ctx.AliasMapRecord.DecompositionType()))
+		}
+		return nil
+	case op.IsAgePrefix(g.SetOperation):
+		for prop := range ctx.DefRecord.AgeDef.EachProperty {
+			_, _ = fmt.Fprintln(g.Writer, ctx.DefRecord.AgeDef.FormatWithAlias(prop, ctx.AliasMapRecord.Age()))
+		}
+		return nil
+	case op.IsBlockPrefix(g.SetOperation):
+		for prop := range ctx.DefRecord.BlockDef.EachProperty {
+			_, _ = fmt.Fprintln(g.Writer, ctx.DefRecord.BlockDef.FormatWithAlias(prop, ctx.AliasMapRecord.Block()))
+		}
+		return nil
+	case op.IsJoiningTypePrefix(g.SetOperation):
+		for prop := range ctx.DefRecord.JoiningTypeDef.EachProperty {
+			_, _ = fmt.Fprintln(g.Writer, ctx.DefRecord.JoiningTypeDef.FormatWithAlias(prop, ctx.AliasMapRecord.JoiningType()))
+		}
+		return nil
+	case op.IsJoiningGroupPrefix(g.SetOperation):
+		for prop := range ctx.DefRecord.JoiningGroupDef.EachProperty {
+			_, _ = fmt.Fprintln(g.Writer, ctx.DefRecord.JoiningGroupDef.FormatWithAlias(prop, ctx.AliasMapRecord.JoiningGroup()))
+		}
+		return nil
+	case op.IsNumericTypePrefix(g.SetOperation):
+		for prop := range ctx.DefRecord.NumericTypeDef.EachProperty {
+			_, _ = fmt.Fprintln(g.Writer, ctx.DefRecord.NumericTypeDef.FormatWithAlias(prop, ctx.AliasMapRecord.NumericType()))
+		}
+		return nil
+	case op.IsNumericValuePrefix(g.SetOperation):
+		for prop := range ctx.DefRecord.NumericValueDef.EachProperty {
+			_, _ = fmt.Fprintln(g.Writer, ctx.DefRecord.NumericValueDef.Format(prop))
+		}
+		return nil
+	case op.IsIdentifierStatusPrefix(g.SetOperation):
+		for prop := range ctx.DefRecord.IdentifierStatusDef.EachProperty {
+			_, _ = fmt.Fprintln(g.Writer, ctx.DefRecord.IdentifierStatusDef.Format(prop))
+		}
+		return nil
+	case op.IsIdentifierTypePrefix(g.SetOperation):
+		for prop := range ctx.DefRecord.IdentifierTypeDef.EachProperty {
+			_, _ = fmt.Fprintln(g.Writer, ctx.DefRecord.IdentifierTypeDef.Format(prop))
+		}
+		return nil
 	}
 	return errors.New(op.UnknowPropertyPrefixError(g.SetOperation))
 }
@@ -453,7 +757,12 @@ func fetchUnicodeData(rev string, output string) error {
 		"Scripts.txt", "ScriptExtensions.txt", "PropList.txt", "DerivedCoreProperties.txt",
 		"emoji/emoji-data.txt", "extracted/DerivedBinaryProperties.txt", "DerivedNormalizationProps.txt",
 		"auxiliary/GraphemeBreakProperty.txt", "auxiliary/WordBreakProperty.txt", "auxiliary/SentenceBreakProperty.txt",
-		"CaseFolding.txt",
+		"ArabicShaping.txt", "extracted/DerivedJoiningType.txt",
+		"extracted/DerivedNumericType.txt", "extracted/DerivedNumericValues.txt", "extracted/DerivedBidiClass.txt",
+		"LineBreak.txt", "IndicSyllabicCategory.txt", "IndicPositionalCategory.txt",
+		"VerticalOrientation.txt", "HangulSyllableType.txt", "BidiMirroring.txt", "BidiBrackets.txt",
+		"CaseFolding.txt", "SpecialCasing.txt", "UnicodeData.txt", "NameAliases.txt",
+		"DerivedAge.txt", "Blocks.txt",
 	}
 	if rev == "latest" {
 		rev = "UCD/latest"
@@ -466,9 +775,13 @@ func fetchUnicodeData(rev string, output string) error {
 			return err
 		}
 	}
+	return fetchAuxiliaryData(rev, output)
+}
 
+// fetchAuxiliaryData download emoji sequences and UTS #39 data (not included in UCD directory)
+func fetchAuxiliaryData(rev string, output string) error {
 	// for emoji sequence
-	targets = []string{
+	targets := []string{
 		"emoji-sequences.txt",
 		"emoji-zwj-sequences.txt",
 	}
@@ -486,5 +799,44 @@ func fetchUnicodeData(rev string, output string) error {
 			return err
 		}
 	}
+
+	// for security (UTS #39)
+	targets = []string{
+		"IdentifierStatus.txt",
+		"IdentifierType.txt",
+		"confusables.txt",
+	}
+	for _, target := range targets {
+		var url string
+		if rev == "UCD/latest" || compareRevision(rev, "17.0.0") >= 0 {
+			url = fmt.Sprintf("https://www.unicode.org/Public/%s/security/%s", rev, target)
+		} else {
+			url = fmt.Sprintf("https://www.unicode.org/Public/security/%s/%s", rev, target)
+		}
+		log.Printf("@@ try downloading %s to %s", url, output)
+		err := fetchContent(url, path.Join(output, path.Base(target)))
+		if err != nil {
+			return err
+		}
+	}
 	return nil
 }
+
+// fetchUCDXML download UCDXML flat file and data not included in it
+func fetchUCDXML(rev string, output string) error {
+	if !revPattern.MatchString(rev) && rev != "latest" {
+		return fmt.Errorf("invalid revision %q", rev)
+	}
+	if rev == "latest" {
+		rev = "UCD/latest"
+	}
+	for _, target := range []string{"ucdxml/ucd.all.flat.zip", "ucd/PropertyValueAliases.txt", "ucd/CaseFolding.txt"} {
+		url := fmt.Sprintf("https://www.unicode.org/Public/%s/%s", rev, target)
+		log.Printf("@@ try downloading %s to %s", url, output)
+		err := fetchContent(url, path.Join(output, path.Base(target)))
+		if err != nil {
+			return err
+		}
+	}
+	return fetchAuxiliaryData(rev, output)
+}
@@ -7,8 +7,11 @@ import (
 	"path"
 	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
 
+	"github.com/sekiguchi-nagisa/guniset/op"
+	"github.com/sekiguchi-nagisa/guniset/set"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -29,6 +32,10 @@ func TestMain(m *testing.M) {
 	if err != nil {
 		log.Fatal(err)
 	}
+	err = fetchUCDXML("16.0.0", outputDir)
+	if err != nil {
+		log.Fatal(err)
+	}
 	exitCode := m.Run()
 	if exitCode == 0 {
 		_ = os.RemoveAll(outputDir)
@@ -38,12 +45,25 @@ func TestMain(m *testing.M) {
 	os.Exit(exitCode)
 }
 
+// ucdxmlContext load UCDXML only once (shared by all test cases)
+var ucdxmlContext = sync.OnceValues(func() (*op.EvalContext, error) {
+	data := op.NewUnicodeData(gUniSetDir)
+	data.UCDXML = op.FindUCDXML(gUniSetDir)
+	return op.NewEvalContext(data)
+})
+
 func runGoldenTest(t *testing.T, baseName string, filterOp SetFilterOp) {
+	runGoldenTestWith(t, path.Join("generate", baseName), func(g *GUniSet) error {
+		return g.RunAndPrint(filterOp)
+	})
+}
+
+func runGoldenTestWith(t *testing.T, dirName string, run func(g *GUniSet) error) {
 	wd, err := os.Getwd()
 	if err != nil {
 		t.Fatal(err)
 	}
-	targetDir := path.Join(wd, "test", "generate", baseName)
+	targetDir := path.Join(wd, "test", dirName)
 	cases, err := filepath.Glob(path.Join(targetDir, "*.test"))
 	if err != nil {
 		t.Fatal(err)
@@ -71,7 +91,7 @@ func runGoldenTest(t *testing.T, baseName string, filterOp SetFilterOp) {
 			if err != nil {
 				t.Fatal(err)
 			}
-			err = g.RunAndPrint(filterOp)
+			err = run(g)
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -126,3 +146,148 @@ func TestPrintDerivedNormalizationProperties(t *testing.T) {
 func TestPrintBreakProperty(t *testing.T) {
 	runGoldenTest(t, "unicode16_break", SetPrintAll)
 }
+
+func TestPrintUCDXML(t *testing.T) {
+	ctx, err := ucdxmlContext()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for baseName, filterOp := range map[string]SetFilterOp{
+		"unicode16": SetPrintAll, "unicode16_long": SetPrintAll, "unicode16_bmp": SetPrintBMP,
+		"unicode16_nonbmp": SetPrintNonBMP, "unicode16_script": SetPrintAll, "unicode16_proplist": SetPrintAll,
+		"unicode16_dcp": SetPrintAll, "unicode16_emoji": SetPrintAll, "unicode16_dbp": SetPrintAll,
+		"unicode16_dnp": SetPrintAll, "unicode16_break": SetPrintAll,
+	} {
+		t.Run(baseName, func(t *testing.T) {
+			runGoldenTestWith(t, path.Join("generate", baseName), func(g *GUniSet) error {
+				g.ctx = ctx // same output as UCD text files
+				return g.RunAndPrint(filterOp)
+			})
+		})
+	}
+}
+
+func runCodeGenGoldenTest(t *testing.T, lang string) {
+	runGoldenTestWith(t, path.Join("codegen", lang), func(g *GUniSet) error {
+		return g.RunAndGenerate(SetPrintAll, &CodeGenOption{Lang: strToCodeGenLang[lang]})
+	})
+}
+
+func TestCodeGenC(t *testing.T) {
+	runCodeGenGoldenTest(t, "c")
+}
+
+func TestCodeGenRust(t *testing.T) {
+	runCodeGenGoldenTest(t, "rust")
+}
+
+func TestCodeGenGo(t *testing.T) {
+	runCodeGenGoldenTest(t, "go")
+}
+
+func TestCodeGenJava(t *testing.T) {
+	runCodeGenGoldenTest(t, "java")
+}
+
+func TestCodeGenPython(t *testing.T) {
+	runCodeGenGoldenTest(t, "python")
+}
+
+func TestCodeGenJavaScript(t *testing.T) {
+	runCodeGenGoldenTest(t, "javascript")
+}
+
+func TestCodeGenTypeScript(t *testing.T) {
+	runCodeGenGoldenTest(t, "typescript")
+}
+
+// parseMappingEntries parse mapping test data. first line is mapping name, and following lines are
+// source code point and mapped code points (or digit value) such as `00DF 0053 0053`
+func parseMappingEntries(data string) (string, []MappingEntry, error) {
+	lines := strings.Split(strings.TrimSpace(data), "\n")
+	var entries []MappingEntry
+	for _, line := range lines[1:] {
+		var runes []rune
+		for _, s := range strings.Fields(line) {
+			r, err := set.ParseRune(s)
+			if err != nil {
+				return "", nil, err
+			}
+			runes = append(runes, r)
+		}
+		entries = append(entries, MappingEntry{From: runes[0], To: runes[1:]})
+	}
+	return strings.TrimSpace(lines[0]), entries, nil
+}
+
+func TestMappingCodeGen(t *testing.T) {
+	for _, lang := range []string{"c", "rust", "go", "java", "python", "javascript", "typescript"} {
+		t.Run(lang, func(t *testing.T) {
+			runGoldenTestWith(t, path.Join("codegen", lang, "mapping"), func(g *GUniSet) error {
+				mapping, entries, err := parseMappingEntries(g.SetOperation)
+				if err != nil {
+					return err
+				}
+				return generateMappingCode(mapping, entries, g.Writer, &CodeGenOption{Lang: strToCodeGenLang[lang]})
+			})
+		})
+	}
+}
+
+func TestMappingDigit(t *testing.T) {
+	writer := strings.Builder{}
+	g, err := NewGUniSetFromDir(gUniSetDir, &writer, "digit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = g.RunAndPrintMapping(SetPrintAll, &CodeGenOption{Lang: LangNone})
+	assert.Nil(t, err)
+	output := writer.String()
+
+	// nt:De
+	for _, entry := range []string{
+		"{ 0x0030, 0 },", "{ 0x0039, 9 },", "{ 0x0669, 9 },", "{ 0xFF10, 0 },", "{ 0x1D7CE, 0 },",
+	} {
+		assert.Contains(t, output, entry)
+	}
+	// not nt:De or not integer
+	for _, r := range []rune{
+		0x00B2, // SUPERSCRIPT TWO (nt:Di)
+		0x00BD, // VULGAR FRACTION ONE HALF (nt:Nu, nv:1/2)
+		0x0F33, // TIBETAN DIGIT HALF ZERO (nt:Nu, nv:-1/2)
+		0x2460, // CIRCLED DIGIT ONE (nt:Di)
+		0x216B, // ROMAN NUMERAL TWELVE (nt:Nu, nv:12)
+	} {
+		assert.NotContains(t, output, fmt.Sprintf("{ 0x%04X,", r))
+	}
+}
+
+func runRegexGoldenTest(t *testing.T, flavor string) {
+	runGoldenTestWith(t, path.Join("regex", flavor), func(g *GUniSet) error {
+		return g.RunAndPrintRegex(SetPrintAll, strToRegexFlavor[flavor])
+	})
+}
+
+func TestRegexPCRE(t *testing.T) {
+	runRegexGoldenTest(t, "pcre")
+}
+
+func TestRegexECMAScript(t *testing.T) {
+	runRegexGoldenTest(t, "ecmascript")
+}
+
+func TestRegexECMAScriptU(t *testing.T) {
+	runRegexGoldenTest(t, "ecmascript-u")
+}
+
+func TestRegexRE2(t *testing.T) {
+	runRegexGoldenTest(t, "re2")
+}
+
+func TestRegexDotNet(t *testing.T) {
+	runRegexGoldenTest(t, "dotnet")
+}
+
+func TestRegexPOSIX(t *testing.T) {
+	runRegexGoldenTest(t, "posix")
+}
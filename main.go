@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"runtime/debug"
@@ -10,8 +11,14 @@ import (
 )
 
 type CLIGen struct {
-	Set    string `arg:"" required:"" help:"Specify set operation"`
+	Set    string `arg:"" optional:"" help:"Specify set operation"`
+	File   string `optional:"" short:"f" type:"path" help:"Read set operation from file"`
+	Strict bool   `optional:"" default:"false" help:"Disable loose matching of property names and values"`
 	Filter string `optional:"" help:"Filter output (all: include all, bmp: only bmp, non-bmp: exclude bmp)" enum:"all,,bmp,non-bmp" default:"all"`
+	Lang   string `optional:"" help:"Emit table for specified language (none, c, rust, go, java, python, javascript, typescript. default: none)" enum:"none,c,rust,go,java,python,javascript,js,typescript,ts" default:"none"`
+	Name   string `optional:"" help:"Specify table name (default: language specific name)"`
+	Decl   string `optional:"" help:"Specify table declaration ('{name}' is replaced with table name)"`
+	Regex  string `optional:"" help:"Emit regex character class (none, pcre, ecmascript, ecmascript-u, re2, dotnet, posix. default: none)" enum:"none,pcre,ecmascript,ecmascript-u,re2,dotnet,posix" default:"none"`
 }
 
 type CLIQuery struct {
@@ -28,6 +35,7 @@ type CLISample struct {
 	Limit  *int     `optional:"" xor:"g" help:"Limit sampling count (default: 5)"`
 	Ratio  *float64 `optional:"" xor:"g" help:"Sampling ratio (up to 1.0)"`
 	Seed   *uint64  `optional:"" help:"Specify random seed. if not specified, use time.Now().UnixNano()"`
+	Strict bool     `optional:"" default:"false" help:"Disable loose matching of property names and values"`
 	Format string   `optional:"" help:"Specify output format (codepoint, string, utf8escape. default: codepoint)" enum:"codepoint,string,utf8escape" default:"codepoint"`
 }
 
@@ -40,20 +48,53 @@ type CLIEnum struct {
 	Property string `arg:"" required:"" help:"Specify enumerating property"`
 }
 
+type CLILookup struct {
+	Property       string `arg:"" required:"" help:"Specify enumerated property (gc, ea, sc, gbp, wbp, sbp)"`
+	Stages         int    `optional:"" help:"Specify number of stages (2 or 3)" default:"2"`
+	BlockSize      int    `optional:"" help:"Specify block size of data stage (power of two)" default:"128"`
+	IndexBlockSize int    `optional:"" help:"Specify block size of intermediate index stage (power of two, only for 3 stages)" default:"32"`
+	Name           string `optional:"" help:"Specify table name (default: property prefix)"`
+}
+
+type CLITable struct {
+	Property string `arg:"" required:"" help:"Specify enumerated property (gc, ea, sc, gbp, wbp, sbp)"`
+	Merge    bool   `optional:"" help:"Merge adjacent ranges that have the same value"`
+	Default  string `optional:"" help:"Specify property value of code points not listed in data files (default: values of @missing lines)"`
+	Strict   bool   `optional:"" default:"false" help:"Disable loose matching of property values"`
+	Name     string `optional:"" help:"Specify table name (default: property prefix)"`
+}
+
+type CLIMapping struct {
+	Mapping string `arg:"" required:"" help:"Specify mapping (mirror, digit, upper, lower, title, fold)" enum:"mirror,digit,upper,lower,title,fold"`
+	Filter  string `optional:"" help:"Filter output (all: include all, bmp: only bmp, non-bmp: exclude bmp)" enum:"all,,bmp,non-bmp" default:"all"`
+	Lang    string `optional:"" help:"Emit table for specified language (none, c, rust, go, java, python, javascript, typescript. default: none)" enum:"none,c,rust,go,java,python,javascript,js,typescript,ts" default:"none"`
+	Name    string `optional:"" help:"Specify table name (default: language specific name)"`
+	Decl    string `optional:"" help:"Specify table declaration ('{name}' is replaced with table name)"`
+}
+
+type CLIConfusables struct {
+	String string `arg:"" required:"" help:"Specify string"`
+}
+
 type CLIDownload struct {
 	Output string `arg:"" help:"Specify output directory name" default:"./"`
 	Rev    string `optional:"" help:"Specify revision" default:"latest"`
 }
 
 var CLI struct {
-	Version  kong.VersionFlag `short:"v" help:"Show version information"`
-	Generate CLIGen           `cmd:"" help:"Generate Unicode set"`
-	Query    CLIQuery         `cmd:"" help:"Query code point property"`
-	Info     CLIInfo          `cmd:"" help:"Show information about Unicode database"`
-	Sample   CLISample        `cmd:"" help:"Sample Unicode code points"`
-	Strings  CLIStrings       `cmd:"" help:"Show Unicode string property"`
-	Enum     CLIEnum          `cmd:"" help:"Enumerate Unicode properties"`
-	Download CLIDownload      `cmd:"" help:"Download Unicode database"`
+	Version     kong.VersionFlag `short:"v" help:"Show version information"`
+	Source      string           `optional:"" help:"Specify data source in GUNISET_DIR (auto: use UCDXML if exists, text: UCD text files, ucdxml: UCDXML flat file)" enum:"auto,text,ucdxml" default:"auto"`
+	Generate    CLIGen           `cmd:"" help:"Generate Unicode set"`
+	Query       CLIQuery         `cmd:"" help:"Query code point property"`
+	Info        CLIInfo          `cmd:"" help:"Show information about Unicode database"`
+	Sample      CLISample        `cmd:"" help:"Sample Unicode code points"`
+	Strings     CLIStrings       `cmd:"" help:"Show Unicode string property"`
+	Enum        CLIEnum          `cmd:"" help:"Enumerate Unicode properties"`
+	Lookup      CLILookup        `cmd:"" help:"Generate multi-stage lookup table of enumerated property"`
+	Table       CLITable         `cmd:"" help:"Generate range to value table of enumerated property"`
+	Mapping     CLIMapping       `cmd:"" help:"Generate code point mapping table"`
+	Confusables CLIConfusables   `cmd:"" help:"Show confusable skeleton and source characters (UTS #39)"`
+	Download    CLIDownload      `cmd:"" help:"Download Unicode database"`
 }
 
 var version = "" // for version embedding (specified like "-X main.version=v0.1.0")
@@ -90,28 +131,52 @@ func resolveGunisetDir() (string, error) {
 	return gunisetDir, nil
 }
 
-func (c *CLIGen) Run() error {
+// newGUniSet create GUniSet from GUNISET_DIR and selected data source
+func newGUniSet(setOperation string) (*GUniSet, error) {
 	gunisetDir, err := resolveGunisetDir()
 	if err != nil {
-		return err
+		return nil, err
+	}
+	g, err := NewGUniSetFromDir(gunisetDir, os.Stdout, setOperation)
+	if err != nil {
+		return nil, err
+	}
+	return g, g.SelectSource(gunisetDir, CLI.Source)
+}
+
+func (c *CLIGen) Run() error {
+	if (c.Set == "") == (c.File == "") {
+		return errors.New("either set operation or --file must be specified")
 	}
-	g, err := NewGUniSetFromDir(gunisetDir, os.Stdout, c.Set)
+	g, err := newGUniSet(c.Set)
 	if err != nil {
 		return err
 	}
+	g.SetOperationFile = c.File
+	g.Strict = c.Strict
 	printOp, ok := StrToSetPrintOps[c.Filter]
 	if !ok {
 		return fmt.Errorf("unknown filter %q\n", c.Filter)
 	}
-	return g.RunAndPrint(printOp)
+	lang, ok := strToCodeGenLang[c.Lang]
+	if !ok {
+		return fmt.Errorf("unknown language %q\n", c.Lang)
+	}
+	flavor, ok := strToRegexFlavor[c.Regex]
+	if !ok {
+		return fmt.Errorf("unknown regex flavor %q\n", c.Regex)
+	}
+	if flavor != RegexNone {
+		if lang != LangNone {
+			return errors.New("--regex and --lang cannot be specified at the same time")
+		}
+		return g.RunAndPrintRegex(printOp, flavor)
+	}
+	return g.RunAndGenerate(printOp, &CodeGenOption{Lang: lang, Name: c.Name, Decl: c.Decl})
 }
 
 func (c *CLIQuery) Run() error {
-	gunisetDir, err := resolveGunisetDir()
-	if err != nil {
-		return err
-	}
-	g, err := NewGUniSetFromDir(gunisetDir, os.Stdout, c.CodePoint)
+	g, err := newGUniSet(c.CodePoint)
 	if err != nil {
 		return err
 	}
@@ -119,11 +184,7 @@ func (c *CLIQuery) Run() error {
 }
 
 func (c *CLIInfo) Run() error {
-	gunisetDir, err := resolveGunisetDir()
-	if err != nil {
-		return err
-	}
-	g, err := NewGUniSetFromDir(gunisetDir, os.Stdout, "")
+	g, err := newGUniSet("")
 	if err != nil {
 		return err
 	}
@@ -131,14 +192,11 @@ func (c *CLIInfo) Run() error {
 }
 
 func (c *CLISample) Run() error {
-	gunisetDir, err := resolveGunisetDir()
-	if err != nil {
-		return err
-	}
-	g, err := NewGUniSetFromDir(gunisetDir, os.Stdout, c.Set)
+	g, err := newGUniSet(c.Set)
 	if err != nil {
 		return err
 	}
+	g.Strict = c.Strict
 	printOp, ok := StrToSetPrintOps[c.Filter]
 	if !ok {
 		return fmt.Errorf("unknown filter %q\n", c.Filter)
@@ -157,11 +215,7 @@ func (c *CLISample) Run() error {
 }
 
 func (c *CLIStrings) Run() error {
-	gunisetDir, err := resolveGunisetDir()
-	if err != nil {
-		return err
-	}
-	g, err := NewGUniSetFromDir(gunisetDir, os.Stdout, c.Property)
+	g, err := newGUniSet(c.Property)
 	if err != nil {
 		return err
 	}
@@ -173,18 +227,67 @@ func (c *CLIStrings) Run() error {
 }
 
 func (c *CLIEnum) Run() error {
-	gunisetDir, err := resolveGunisetDir()
+	g, err := newGUniSet(c.Property)
 	if err != nil {
 		return err
 	}
-	g, err := NewGUniSetFromDir(gunisetDir, os.Stdout, c.Property)
+	return g.EnumerateProperty()
+}
+
+func (c *CLILookup) Run() error {
+	g, err := newGUniSet(c.Property)
 	if err != nil {
 		return err
 	}
-	return g.EnumerateProperty()
+	var blockSizes []int
+	switch c.Stages {
+	case 2:
+		blockSizes = []int{c.BlockSize}
+	case 3:
+		blockSizes = []int{c.BlockSize, c.IndexBlockSize}
+	default:
+		return fmt.Errorf("unsupported stages: %d, must be 2 or 3", c.Stages)
+	}
+	return g.RunAndPrintLookupTable(c.Name, blockSizes)
+}
+
+func (c *CLITable) Run() error {
+	g, err := newGUniSet(c.Property)
+	if err != nil {
+		return err
+	}
+	g.Strict = c.Strict
+	return g.RunAndPrintPropertyTable(c.Name, c.Default, c.Merge)
+}
+
+func (c *CLIConfusables) Run() error {
+	g, err := newGUniSet(c.String)
+	if err != nil {
+		return err
+	}
+	return g.Confusables()
+}
+
+func (c *CLIMapping) Run() error {
+	g, err := newGUniSet(c.Mapping)
+	if err != nil {
+		return err
+	}
+	printOp, ok := StrToSetPrintOps[c.Filter]
+	if !ok {
+		return fmt.Errorf("unknown filter %q\n", c.Filter)
+	}
+	lang, ok := strToCodeGenLang[c.Lang]
+	if !ok {
+		return fmt.Errorf("unknown language %q\n", c.Lang)
+	}
+	return g.RunAndPrintMapping(printOp, &CodeGenOption{Lang: lang, Name: c.Name, Decl: c.Decl})
 }
 
 func (c *CLIDownload) Run() error {
+	if CLI.Source == SourceUCDXML {
+		return fetchUCDXML(c.Rev, c.Output)
+	}
 	return fetchUnicodeData(c.Rev, c.Output)
 }
 
@@ -0,0 +1,368 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/sekiguchi-nagisa/guniset/op"
+)
+
+// MappingEntry code point to code point (or code point sequence) mapping entry
+type MappingEntry struct {
+	From rune
+	To   []rune
+}
+
+var mappingCodeGenTemplates = map[CodeGenLang]codeGenTemplate{
+	LangC: {
+		name:  "mapping",
+		decl:  "static const struct { unsigned int from; unsigned int to; } {name}[] = {",
+		entry: "    { 0x%04X, 0x%04X },\n",
+		close: "};",
+	},
+	LangRust: {
+		name:  "MAPPING",
+		decl:  "pub static {name}: &[(char, char)] = &[",
+		entry: "    ('\\u{%04X}', '\\u{%04X}'),\n",
+		close: "];",
+	},
+	LangGo: {
+		name:  "mapping",
+		decl:  "var {name} = map[rune]rune{",
+		entry: "\t0x%04X: 0x%04X,\n",
+		close: "}",
+	},
+	LangJava: {
+		name:  "MAPPING",
+		decl:  "static final int[][] {name} = {",
+		entry: "    { 0x%04X, 0x%04X },\n",
+		close: "};",
+	},
+	LangPython: {
+		name:  "MAPPING",
+		decl:  "{name} = {",
+		entry: "    0x%04X: 0x%04X,\n",
+		close: "}",
+	},
+	LangJavaScript: {
+		name:  "MAPPING",
+		decl:  "export const {name} = new Map([",
+		entry: "    [0x%04X, 0x%04X],\n",
+		close: "]);",
+	},
+	LangTypeScript: {
+		name:  "MAPPING",
+		decl:  "export const {name}: ReadonlyMap<number, number> = new Map([",
+		entry: "    [0x%04X, 0x%04X],\n",
+		close: "]);",
+	},
+}
+
+var digitValueCodeGenTemplates = map[CodeGenLang]codeGenTemplate{
+	LangC: {
+		name:  "digit_value",
+		decl:  "static const struct { unsigned int code; unsigned char value; } {name}[] = {",
+		entry: "    { 0x%04X, %d },\n",
+		close: "};",
+	},
+	LangRust: {
+		name:  "DIGIT_VALUE",
+		decl:  "pub static {name}: &[(char, u8)] = &[",
+		entry: "    ('\\u{%04X}', %d),\n",
+		close: "];",
+	},
+	LangGo: {
+		name:  "digitValue",
+		decl:  "var {name} = map[rune]int{",
+		entry: "\t0x%04X: %d,\n",
+		close: "}",
+	},
+	LangJava: {
+		name:  "DIGIT_VALUE",
+		decl:  "static final int[][] {name} = {",
+		entry: "    { 0x%04X, %d },\n",
+		close: "};",
+	},
+	LangPython: {
+		name:  "DIGIT_VALUE",
+		decl:  "{name} = {",
+		entry: "    0x%04X: %d,\n",
+		close: "}",
+	},
+	LangJavaScript: {
+		name:  "DIGIT_VALUE",
+		decl:  "export const {name} = new Map([",
+		entry: "    [0x%04X, %d],\n",
+		close: "]);",
+	},
+	LangTypeScript: {
+		name:  "DIGIT_VALUE",
+		decl:  "export const {name}: ReadonlyMap<number, number> = new Map([",
+		entry: "    [0x%04X, %d],\n",
+		close: "]);",
+	},
+}
+
+func generateRuneMappingCode(entries []MappingEntry, writer io.Writer, option *CodeGenOption,
+	templates map[CodeGenLang]codeGenTemplate, noneEntry string) error {
+	if option.Lang == LangNone {
+		for _, e := range entries {
+			_, err := fmt.Fprintf(writer, noneEntry, e.From, e.To[0])
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	t, ok := templates[option.Lang]
+	if !ok {
+		return fmt.Errorf("unsupported language: %d", option.Lang)
+	}
+	_, err := fmt.Fprintln(writer, option.resolveDecl(&t))
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		_, err = fmt.Fprintf(writer, t.entry, e.From, e.To[0])
+		if err != nil {
+			return err
+		}
+	}
+	_, err = fmt.Fprintln(writer, t.close)
+	return err
+}
+
+func GenerateMappingCode(entries []MappingEntry, writer io.Writer, option *CodeGenOption) error {
+	return generateRuneMappingCode(entries, writer, option, mappingCodeGenTemplates, "{ 0x%04X, 0x%04X },\n")
+}
+
+// GenerateDigitValueCode generate code point to digit value table. digit value is stored in MappingEntry.To
+func GenerateDigitValueCode(entries []MappingEntry, writer io.Writer, option *CodeGenOption) error {
+	return generateRuneMappingCode(entries, writer, option, digitValueCodeGenTemplates, "{ 0x%04X, %d },\n")
+}
+
+type stringMappingCodeGenTemplate struct {
+	codeGenTemplate
+	escape func([]rune) string // convert mapped code points to language specific literal content
+}
+
+func escapeUTF8Bytes(runes []rune) string {
+	sb := strings.Builder{}
+	for _, b := range []byte(string(runes)) {
+		_, _ = fmt.Fprintf(&sb, "\\x%02X", b)
+	}
+	return sb.String()
+}
+
+// escapeBracedUnicode escape code points like `\u{1F600}` (Rust, JavaScript)
+func escapeBracedUnicode(runes []rune) string {
+	sb := strings.Builder{}
+	for _, r := range runes {
+		_, _ = fmt.Fprintf(&sb, "\\u{%04X}", r)
+	}
+	return sb.String()
+}
+
+// escapeUnicode escape code points like `\u00DF` or `\U0001F600` (Go, Python)
+func escapeUnicode(runes []rune) string {
+	sb := strings.Builder{}
+	for _, r := range runes {
+		if r > 0xFFFF {
+			_, _ = fmt.Fprintf(&sb, "\\U%08X", r)
+		} else {
+			_, _ = fmt.Fprintf(&sb, "\\u%04X", r)
+		}
+	}
+	return sb.String()
+}
+
+func joinCodePoints(runes []rune, sep string) string {
+	var ss []string
+	for _, r := range runes {
+		ss = append(ss, fmt.Sprintf("0x%04X", r))
+	}
+	return strings.Join(ss, sep)
+}
+
+var stringMappingCodeGenTemplates = map[CodeGenLang]stringMappingCodeGenTemplate{
+	LangC: {
+		codeGenTemplate: codeGenTemplate{
+			name:  "mapping",
+			decl:  "static const struct { unsigned int from; const char *to; } {name}[] = {",
+			entry: "    { 0x%04X, \"%s\" },\n",
+			close: "};",
+		},
+		escape: escapeUTF8Bytes,
+	},
+	LangRust: {
+		codeGenTemplate: codeGenTemplate{
+			name:  "MAPPING",
+			decl:  "pub static {name}: &[(char, &str)] = &[",
+			entry: "    ('\\u{%04X}', \"%s\"),\n",
+			close: "];",
+		},
+		escape: escapeBracedUnicode,
+	},
+	LangGo: {
+		codeGenTemplate: codeGenTemplate{
+			name:  "mapping",
+			decl:  "var {name} = map[rune]string{",
+			entry: "\t0x%04X: \"%s\",\n",
+			close: "}",
+		},
+		escape: escapeUnicode,
+	},
+	LangJava: {
+		codeGenTemplate: codeGenTemplate{
+			name:  "MAPPING",
+			decl:  "static final int[][] {name} = {",
+			entry: "    { 0x%04X, %s },\n",
+			close: "};",
+		},
+		escape: func(runes []rune) string {
+			return joinCodePoints(runes, ", ")
+		},
+	},
+	LangPython: {
+		codeGenTemplate: codeGenTemplate{
+			name:  "MAPPING",
+			decl:  "{name} = {",
+			entry: "    0x%04X: \"%s\",\n",
+			close: "}",
+		},
+		escape: escapeUnicode,
+	},
+	LangJavaScript: {
+		codeGenTemplate: codeGenTemplate{
+			name:  "MAPPING",
+			decl:  "export const {name} = new Map([",
+			entry: "    [0x%04X, \"%s\"],\n",
+			close: "]);",
+		},
+		escape: escapeBracedUnicode,
+	},
+	LangTypeScript: {
+		codeGenTemplate: codeGenTemplate{
+			name:  "MAPPING",
+			decl:  "export const {name}: ReadonlyMap<number, string> = new Map([",
+			entry: "    [0x%04X, \"%s\"],\n",
+			close: "]);",
+		},
+		escape: escapeBracedUnicode,
+	},
+}
+
+// GenerateStringMappingCode generate code point to string mapping table (for full case mapping)
+func GenerateStringMappingCode(entries []MappingEntry, writer io.Writer, option *CodeGenOption) error {
+	if option.Lang == LangNone {
+		for _, e := range entries {
+			_, err := fmt.Fprintf(writer, "{ 0x%04X, %s },\n", e.From, joinCodePoints(e.To, " "))
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	t, ok := stringMappingCodeGenTemplates[option.Lang]
+	if !ok {
+		return fmt.Errorf("unsupported language: %d", option.Lang)
+	}
+	_, err := fmt.Fprintln(writer, option.resolveDecl(&t.codeGenTemplate))
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		_, err = fmt.Fprintf(writer, t.entry, e.From, t.escape(e.To))
+		if err != nil {
+			return err
+		}
+	}
+	_, err = fmt.Fprintln(writer, t.close)
+	return err
+}
+
+// sortedMappingEntries returns mapping entries sorted by source code point
+func sortedMappingEntries(runeMap op.RuneStringMap, filterOp SetFilterOp) []MappingEntry {
+	var entries []MappingEntry
+	for _, from := range slices.Sorted(maps.Keys(runeMap)) {
+		switch {
+		case filterOp == SetPrintBMP && from > 0xFFFF:
+			continue
+		case filterOp == SetPrintNonBMP && from <= 0xFFFF:
+			continue
+		}
+		entries = append(entries, MappingEntry{From: from, To: runeMap[from]})
+	}
+	return entries
+}
+
+// digitValueMap returns digit values of Numeric_Type=Decimal code points
+func digitValueMap(ctx *op.EvalContext) (op.RuneStringMap, error) {
+	decimal, err := ctx.DefRecord.NumericTypeDef.ParseWithAlias("De", ctx.AliasMapRecord.NumericType())
+	if err != nil {
+		return nil, err
+	}
+	runeMap := op.RuneStringMap{}
+	decimalSet, ok := ctx.NumericTypeMap[decimal]
+	if !ok {
+		return runeMap, nil
+	}
+	for nv, uniSet := range ctx.NumericValueMap {
+		value, err := strconv.Atoi(ctx.DefRecord.NumericValueDef.GetName(nv))
+		if err != nil { // not integer (such as `1/2`, `NaN`)
+			continue
+		}
+		digits := uniSet.AndSet(decimalSet)
+		for r := range digits.Iter {
+			runeMap[r] = []rune{rune(value)}
+		}
+	}
+	return runeMap, nil
+}
+
+// generateMappingCode generate mapping table of mapping (mirror, digit, upper, lower, title or fold)
+func generateMappingCode(mapping string, entries []MappingEntry, writer io.Writer, option *CodeGenOption) error {
+	switch mapping {
+	case "mirror":
+		return GenerateMappingCode(entries, writer, option)
+	case "digit":
+		return GenerateDigitValueCode(entries, writer, option)
+	default:
+		return GenerateStringMappingCode(entries, writer, option)
+	}
+}
+
+func (g *GUniSet) RunAndPrintMapping(filterOp SetFilterOp, option *CodeGenOption) error {
+	ctx, err := g.prepare()
+	if err != nil {
+		return err
+	}
+	var runeMap op.RuneStringMap
+	switch g.SetOperation {
+	case "mirror":
+		runeMap = op.RuneStringMap{}
+		for from, to := range ctx.BidiMirroringMap {
+			runeMap[from] = []rune{to}
+		}
+	case "digit":
+		runeMap, err = digitValueMap(ctx)
+		if err != nil {
+			return err
+		}
+	case "upper":
+		runeMap = ctx.CaseMapping.UpperMap()
+	case "lower":
+		runeMap = ctx.CaseMapping.LowerMap()
+	case "title":
+		runeMap = ctx.CaseMapping.TitleMap()
+	case "fold":
+		runeMap = ctx.CaseFoldingMap.FullFoldMap()
+	default:
+		return fmt.Errorf("unknown mapping: %s, must be `mirror`, `digit`, `upper`, `lower`, `title` or `fold`", g.SetOperation)
+	}
+	return generateMappingCode(g.SetOperation, sortedMappingEntries(runeMap, filterOp), g.Writer, option)
+}
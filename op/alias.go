@@ -6,20 +6,23 @@ import (
 )
 
 type AliasMap struct {
-	abbrToLong map[string][]string
-	longToAbbr map[string]string
+	abbrToLong  map[string][]string
+	longToAbbr  map[string]string
+	looseToAbbr map[string]string // for loose matching (UAX44-LM3)
 }
 
 func NewAliasMap() *AliasMap {
 	return &AliasMap{
-		abbrToLong: make(map[string][]string),
-		longToAbbr: make(map[string]string),
+		abbrToLong:  make(map[string][]string),
+		longToAbbr:  make(map[string]string),
+		looseToAbbr: make(map[string]string),
 	}
 }
 
 func (a *AliasMap) Add(abbr string, long string) {
 	a.abbrToLong[abbr] = append(a.abbrToLong[abbr], long)
 	a.longToAbbr[long] = abbr
+	a.addLoose(abbr, long)
 }
 
 func (a *AliasMap) AddAll(abbr string, longs []string) {
@@ -27,6 +30,15 @@ func (a *AliasMap) AddAll(abbr string, longs []string) {
 	for _, long := range longs {
 		a.longToAbbr[long] = abbr
 	}
+	a.addLoose(abbr, longs...)
+}
+
+func (a *AliasMap) addLoose(abbr string, longs ...string) {
+	for _, name := range append([]string{abbr}, longs...) {
+		if _, ok := a.looseToAbbr[looseMatchKey(name)]; !ok { // first definition wins
+			a.looseToAbbr[looseMatchKey(name)] = abbr
+		}
+	}
 }
 
 func (a *AliasMap) Lookup(abbr string) []string {
@@ -37,19 +49,54 @@ func (a *AliasMap) LookupAbbr(long string) string {
 	return a.longToAbbr[long]
 }
 
+// LookupAbbrLoose lookup abbreviation of abbr or long name by loose matching
+func (a *AliasMap) LookupAbbrLoose(name string) string {
+	return a.looseToAbbr[looseMatchKey(name)]
+}
+
 type AliasMaps = map[string]*AliasMap
 
 type AliasMapRecord struct {
-	gc *AliasMap
-	ea *AliasMap
-	sc *AliasMap
+	gc  *AliasMap
+	ea  *AliasMap
+	sc  *AliasMap
+	bc  *AliasMap
+	ccc *AliasMap
+	dt  *AliasMap
+	age *AliasMap
+	blk *AliasMap
+	lb  *AliasMap
+	vo  *AliasMap
+	hst *AliasMap
+	bpt *AliasMap
+	nt  *AliasMap
+	jt  *AliasMap
+	jg  *AliasMap
+	gcb *AliasMap // Grapheme_Cluster_Break (for UCDXML)
+	wb  *AliasMap // Word_Break (for UCDXML)
+	sb  *AliasMap // Sentence_Break (for UCDXML)
 }
 
 func NewAliasMapRecord() *AliasMapRecord {
 	return &AliasMapRecord{
-		gc: NewAliasMap(),
-		ea: NewAliasMap(),
-		sc: NewAliasMap(),
+		gc:  NewAliasMap(),
+		ea:  NewAliasMap(),
+		sc:  NewAliasMap(),
+		bc:  NewAliasMap(),
+		ccc: NewAliasMap(),
+		dt:  NewAliasMap(),
+		age: NewAliasMap(),
+		blk: NewAliasMap(),
+		lb:  NewAliasMap(),
+		vo:  NewAliasMap(),
+		hst: NewAliasMap(),
+		bpt: NewAliasMap(),
+		nt:  NewAliasMap(),
+		jt:  NewAliasMap(),
+		jg:  NewAliasMap(),
+		gcb: NewAliasMap(),
+		wb:  NewAliasMap(),
+		sb:  NewAliasMap(),
 	}
 }
 
@@ -65,10 +112,92 @@ func (a *AliasMapRecord) Script() *AliasMap {
 	return a.sc
 }
 
+func (a *AliasMapRecord) BidiClass() *AliasMap {
+	return a.bc
+}
+
+func (a *AliasMapRecord) CombiningClass() *AliasMap {
+	return a.ccc
+}
+
+func (a *AliasMapRecord) DecompositionType() *AliasMap {
+	return a.dt
+}
+
+func (a *AliasMapRecord) Age() *AliasMap {
+	return a.age
+}
+
+func (a *AliasMapRecord) Block() *AliasMap {
+	return a.blk
+}
+
+func (a *AliasMapRecord) LineBreak() *AliasMap {
+	return a.lb
+}
+
+func (a *AliasMapRecord) VerticalOrientation() *AliasMap {
+	return a.vo
+}
+
+func (a *AliasMapRecord) HangulSyllableType() *AliasMap {
+	return a.hst
+}
+
+func (a *AliasMapRecord) BidiPairedBracketType() *AliasMap {
+	return a.bpt
+}
+
+func (a *AliasMapRecord) NumericType() *AliasMap {
+	return a.nt
+}
+
+func (a *AliasMapRecord) JoiningType() *AliasMap {
+	return a.jt
+}
+
+func (a *AliasMapRecord) JoiningGroup() *AliasMap {
+	return a.jg
+}
+
+func (a *AliasMapRecord) GraphemeBreak() *AliasMap {
+	return a.gcb
+}
+
+func (a *AliasMapRecord) WordBreak() *AliasMap {
+	return a.wb
+}
+
+func (a *AliasMapRecord) SentenceBreak() *AliasMap {
+	return a.sb
+}
+
+// property names of PropertyValueAliases.txt that differ from prefixes
+const (
+	graphemeBreakAliasTarget = "GCB"
+	wordBreakAliasTarget     = "WB"
+	sentenceBreakAliasTarget = "SB"
+)
+
 var aliasTargetPrefixes = map[string]struct{}{
-	GeneralCategoryPrefix: {},
-	EastAsianWidthPrefix:  {},
-	ScriptPrefix:          {},
+	GeneralCategoryPrefix:       {},
+	EastAsianWidthPrefix:        {},
+	ScriptPrefix:                {},
+	BidiClassPrefix:             {},
+	CombiningClassPrefix:        {},
+	DecompositionTypePrefix:     {},
+	AgePrefix:                   {},
+	BlockPrefix:                 {},
+	LineBreakPrefix:             {},
+	VerticalOrientationPrefix:   {},
+	HangulSyllableTypePrefix:    {},
+	BidiPairedBracketTypePrefix: {},
+	NumericTypePrefix:           {},
+	JoiningTypePrefix:           {},
+	JoiningGroupPrefix:          {},
+	graphemeBreakAliasTarget:    {},
+	wordBreakAliasTarget:        {},
+	sentenceBreakAliasTarget:    {},
 }
 
 func ParseAliasEntry(line string) (struct {
@@ -103,6 +232,36 @@ func (a *AliasMapRecord) Define(prefix string, abbr string, longs []string) erro
 		a.ea.AddAll(abbr, longs)
 	case ScriptPrefix:
 		a.sc.AddAll(abbr, longs)
+	case BidiClassPrefix:
+		a.bc.AddAll(abbr, longs)
+	case CombiningClassPrefix:
+		a.ccc.AddAll(abbr, longs)
+	case DecompositionTypePrefix:
+		a.dt.AddAll(abbr, longs)
+	case AgePrefix:
+		a.age.AddAll(abbr, longs)
+	case BlockPrefix:
+		a.blk.AddAll(abbr, longs)
+	case LineBreakPrefix:
+		a.lb.AddAll(abbr, longs)
+	case VerticalOrientationPrefix:
+		a.vo.AddAll(abbr, longs)
+	case HangulSyllableTypePrefix:
+		a.hst.AddAll(abbr, longs)
+	case BidiPairedBracketTypePrefix:
+		a.bpt.AddAll(abbr, longs)
+	case NumericTypePrefix:
+		a.nt.AddAll(abbr, longs)
+	case JoiningTypePrefix:
+		a.jt.AddAll(abbr, longs)
+	case JoiningGroupPrefix:
+		a.jg.AddAll(abbr, longs)
+	case graphemeBreakAliasTarget:
+		a.gcb.AddAll(abbr, longs)
+	case wordBreakAliasTarget:
+		a.wb.AddAll(abbr, longs)
+	case sentenceBreakAliasTarget:
+		a.sb.AddAll(abbr, longs)
 	default:
 		return fmt.Errorf("unknown prefix: %s", prefix)
 	}
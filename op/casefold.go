@@ -1,8 +1,15 @@
 package op
 
+import (
+	"maps"
+	"slices"
+)
+
 type CaseFoldMap struct {
 	fold   map[rune]rune
 	unfold map[rune][]rune
+	full   RuneStringMap // for full case folding (status F)
+	turkic map[rune]rune // for Turkic case folding (status T)
 }
 
 func NewCaseFoldMap(runes [][2]rune) *CaseFoldMap {
@@ -31,3 +38,138 @@ func (m *CaseFoldMap) LookupUnfold(r rune) []rune {
 	}
 	return []rune{r}
 }
+
+// AddFull add full case folding (status F) mapping
+func (m *CaseFoldMap) AddFull(r rune, folded []rune) {
+	if m.full == nil {
+		m.full = make(RuneStringMap)
+	}
+	m.full[r] = folded
+}
+
+// LookupFullFold returns full case folding (status C + F)
+func (m *CaseFoldMap) LookupFullFold(r rune) []rune {
+	if a, ok := m.full[r]; ok {
+		return a
+	}
+	return []rune{m.LookupFold(r)}
+}
+
+// FullFoldMap returns full case folding of code points that are changed by folding
+func (m *CaseFoldMap) FullFoldMap() RuneStringMap {
+	ret := make(RuneStringMap)
+	for r, a := range m.fold {
+		ret[r] = []rune{a}
+	}
+	for r, a := range m.full {
+		ret[r] = a
+	}
+	return ret
+}
+
+// AddTurkic add Turkic specific case folding (status T) mapping
+func (m *CaseFoldMap) AddTurkic(r rune, folded rune) {
+	if m.turkic == nil {
+		m.turkic = make(map[rune]rune)
+	}
+	m.turkic[r] = folded
+}
+
+// lookupFoldWith returns simple case folding. if turkic is true, Turkic mappings (status T) are used
+// instead of normal mappings (status C)
+func (m *CaseFoldMap) lookupFoldWith(r rune, turkic bool) rune {
+	if turkic {
+		if a, ok := m.turkic[r]; ok {
+			return a
+		}
+	}
+	return m.LookupFold(r)
+}
+
+// Closure returns code points equivalent to r under simple case folding (including r itself).
+// if turkic is true, use Turkic mappings (status T) instead of normal mappings (status C)
+func (m *CaseFoldMap) Closure(r rune, turkic bool) []rune {
+	folded := m.lookupFoldWith(r, turkic)
+	ret := []rune{r}
+	add := func(c rune) {
+		if c != r && m.lookupFoldWith(c, turkic) == folded && !slices.Contains(ret, c) {
+			ret = append(ret, c)
+		}
+	}
+	add(folded)
+	for _, c := range m.unfold[folded] {
+		add(c)
+	}
+	if turkic {
+		for _, c := range slices.Sorted(maps.Keys(m.turkic)) {
+			add(c)
+		}
+	}
+	return ret
+}
+
+// RuneStringMap code point to string (code point sequence) mapping
+type RuneStringMap = map[rune][]rune
+
+// CaseMapping full case mapping (UnicodeData.txt and unconditional mappings in SpecialCasing.txt).
+// code points mapped to themselves are not stored
+type CaseMapping struct {
+	upper RuneStringMap
+	lower RuneStringMap
+	title RuneStringMap
+}
+
+func NewCaseMapping() *CaseMapping {
+	return &CaseMapping{upper: make(RuneStringMap), lower: make(RuneStringMap), title: make(RuneStringMap)}
+}
+
+func setCaseMapping(m RuneStringMap, r rune, mapped []rune) {
+	if len(mapped) == 1 && mapped[0] == r {
+		delete(m, r)
+	} else {
+		m[r] = mapped
+	}
+}
+
+func (c *CaseMapping) SetUpper(r rune, mapped []rune) {
+	setCaseMapping(c.upper, r, mapped)
+}
+
+func (c *CaseMapping) SetLower(r rune, mapped []rune) {
+	setCaseMapping(c.lower, r, mapped)
+}
+
+func (c *CaseMapping) SetTitle(r rune, mapped []rune) {
+	setCaseMapping(c.title, r, mapped)
+}
+
+func lookupCaseMapping(m RuneStringMap, r rune) []rune {
+	if a, ok := m[r]; ok {
+		return a
+	}
+	return []rune{r}
+}
+
+func (c *CaseMapping) LookupUpper(r rune) []rune {
+	return lookupCaseMapping(c.upper, r)
+}
+
+func (c *CaseMapping) LookupLower(r rune) []rune {
+	return lookupCaseMapping(c.lower, r)
+}
+
+func (c *CaseMapping) LookupTitle(r rune) []rune {
+	return lookupCaseMapping(c.title, r)
+}
+
+func (c *CaseMapping) UpperMap() RuneStringMap {
+	return c.upper
+}
+
+func (c *CaseMapping) LowerMap() RuneStringMap {
+	return c.lower
+}
+
+func (c *CaseMapping) TitleMap() RuneStringMap {
+	return c.title
+}
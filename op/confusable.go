@@ -0,0 +1,57 @@
+package op
+
+import (
+	"maps"
+	"slices"
+
+	"github.com/sekiguchi-nagisa/guniset/set"
+)
+
+// Confusables prototype mappings defined in confusables.txt (UTS #39)
+type Confusables struct {
+	prototypes RuneStringMap
+}
+
+func NewConfusables(prototypes RuneStringMap) *Confusables {
+	return &Confusables{prototypes: prototypes}
+}
+
+// Skeleton computes skeleton of runes.
+// skeleton(X) = NFD(prototype mapping of each code point of NFD(X) except for ignorables (Default_Ignorable_Code_Point))
+func (c *Confusables) Skeleton(runes []rune, normalizer *Normalizer, ignorables *set.UniSet) []rune {
+	var mapped []rune
+	for _, r := range normalizer.NFD(runes) {
+		if ignorables != nil && ignorables.Find(r) {
+			continue
+		}
+		if prototype, ok := c.prototypes[r]; ok {
+			mapped = append(mapped, prototype...)
+		} else {
+			mapped = append(mapped, r)
+		}
+	}
+	return normalizer.NFD(mapped)
+}
+
+// Sources returns source code points (listed in confusables.txt) whose skeleton is equivalent to skeleton
+func (c *Confusables) Sources(skeleton []rune, normalizer *Normalizer, ignorables *set.UniSet) []rune {
+	var sources []rune
+	for _, r := range slices.Sorted(maps.Keys(c.prototypes)) {
+		if slices.Equal(c.Skeleton([]rune{r}, normalizer, ignorables), skeleton) {
+			sources = append(sources, r)
+		}
+	}
+	return sources
+}
+
+// DefaultIgnorables returns code points of Default_Ignorable_Code_Point (removed from skeleton)
+func (e *EvalContext) DefaultIgnorables() *set.UniSet {
+	if e.DefRecord.DerivedCorePropDef == nil {
+		return nil
+	}
+	p, err := e.DefRecord.DerivedCorePropDef.Parse("Default_Ignorable_Code_Point")
+	if err != nil {
+		return nil
+	}
+	return e.DerivedCorePropMap[p]
+}
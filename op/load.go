@@ -2,12 +2,16 @@ package op
 
 import (
 	"bufio"
+	"cmp"
 	"fmt"
 	"io"
+	"maps"
 	"os"
 	"path"
 	"slices"
+	"strconv"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/sekiguchi-nagisa/guniset/set"
 )
@@ -18,7 +22,16 @@ type DataHeader struct {
 }
 
 type DataHeaders struct {
-	List []DataHeader
+	List     []DataHeader
+	Unlisted map[string]*set.UniSet // code points not listed in data file (filled by @missing lines), keyed by file path
+}
+
+// addUnlisted record code points not contained in found (listed code points of filename)
+func (d *DataHeaders) addUnlisted(filename string, found *set.UniSet) {
+	if d.Unlisted == nil {
+		d.Unlisted = map[string]*set.UniSet{}
+	}
+	d.Unlisted[filename] = new(found.Complement())
 }
 
 func (d *DataHeaders) Print(writer io.Writer) error {
@@ -48,6 +61,27 @@ type UnicodeData struct {
 	CaseFolding               string // CaseFolding.txt
 	EmojiSequences            string // emoji-sequences.txt
 	EmojiZwjSequences         string // emoji-zwj-sequences.txt
+	UnicodeData               string // UnicodeData.txt
+	NameAliases               string // NameAliases.txt
+	DerivedAge                string // DerivedAge.txt
+	Blocks                    string // Blocks.txt
+	LineBreak                 string // LineBreak.txt
+	IndicSyllabicCategory     string // IndicSyllabicCategory.txt
+	IndicPositionalCategory   string // IndicPositionalCategory.txt
+	VerticalOrientation       string // VerticalOrientation.txt
+	HangulSyllableType        string // HangulSyllableType.txt
+	BidiMirroring             string // BidiMirroring.txt
+	BidiBrackets              string // BidiBrackets.txt
+	SpecialCasing             string // SpecialCasing.txt
+	DerivedJoiningType        string // DerivedJoiningType.txt
+	ArabicShaping             string // ArabicShaping.txt
+	DerivedNumericType        string // DerivedNumericType.txt
+	DerivedNumericValues      string // DerivedNumericValues.txt
+	DerivedBidiClass          string // DerivedBidiClass.txt
+	IdentifierStatus          string // IdentifierStatus.txt
+	IdentifierType            string // IdentifierType.txt
+	Confusables               string // confusables.txt
+	UCDXML                    string // ucd.all.flat.xml (if not empty, used instead of UCD text files)
 }
 
 func NewUnicodeData(unicodeDir string) *UnicodeData {
@@ -68,6 +102,26 @@ func NewUnicodeData(unicodeDir string) *UnicodeData {
 		CaseFolding:               path.Join(unicodeDir, "CaseFolding.txt"),
 		EmojiSequences:            path.Join(unicodeDir, "emoji-sequences.txt"),
 		EmojiZwjSequences:         path.Join(unicodeDir, "emoji-zwj-sequences.txt"),
+		UnicodeData:               path.Join(unicodeDir, "UnicodeData.txt"),
+		NameAliases:               path.Join(unicodeDir, "NameAliases.txt"),
+		DerivedAge:                path.Join(unicodeDir, "DerivedAge.txt"),
+		Blocks:                    path.Join(unicodeDir, "Blocks.txt"),
+		LineBreak:                 path.Join(unicodeDir, "LineBreak.txt"),
+		IndicSyllabicCategory:     path.Join(unicodeDir, "IndicSyllabicCategory.txt"),
+		IndicPositionalCategory:   path.Join(unicodeDir, "IndicPositionalCategory.txt"),
+		VerticalOrientation:       path.Join(unicodeDir, "VerticalOrientation.txt"),
+		HangulSyllableType:        path.Join(unicodeDir, "HangulSyllableType.txt"),
+		BidiMirroring:             path.Join(unicodeDir, "BidiMirroring.txt"),
+		BidiBrackets:              path.Join(unicodeDir, "BidiBrackets.txt"),
+		SpecialCasing:             path.Join(unicodeDir, "SpecialCasing.txt"),
+		DerivedJoiningType:        path.Join(unicodeDir, "DerivedJoiningType.txt"),
+		ArabicShaping:             path.Join(unicodeDir, "ArabicShaping.txt"),
+		DerivedNumericType:        path.Join(unicodeDir, "DerivedNumericType.txt"),
+		DerivedNumericValues:      path.Join(unicodeDir, "DerivedNumericValues.txt"),
+		DerivedBidiClass:          path.Join(unicodeDir, "DerivedBidiClass.txt"),
+		IdentifierStatus:          path.Join(unicodeDir, "IdentifierStatus.txt"),
+		IdentifierType:            path.Join(unicodeDir, "IdentifierType.txt"),
+		Confusables:               path.Join(unicodeDir, "confusables.txt"),
 	}
 }
 
@@ -83,6 +137,23 @@ type DefRecord struct {
 	GraphemeBreakPropDef        *PropertyDef[GraphemeBreakProperty]
 	WordBreakPropDef            *PropertyDef[WordBreakProperty]
 	SentenceBreakPropDef        *PropertyDef[SentenceBreakProperty]
+	LineBreakDef                *PropertyDef[LineBreak]
+	IndicSyllabicCategoryDef    *PropertyDef[IndicSyllabicCategory]
+	IndicPositionalCategoryDef  *PropertyDef[IndicPositionalCategory]
+	VerticalOrientationDef      *PropertyDef[VerticalOrientation]
+	HangulSyllableTypeDef       *PropertyDef[HangulSyllableType]
+	BidiClassDef                *PropertyDef[BidiClass]
+	CombiningClassDef           *PropertyDef[CombiningClass]
+	DecompositionTypeDef        *PropertyDef[DecompositionType]
+	BidiPairedBracketTypeDef    *PropertyDef[BidiPairedBracketType]
+	AgeDef                      *PropertyDef[Age]
+	BlockDef                    *PropertyDef[Block]
+	JoiningTypeDef              *PropertyDef[JoiningType]
+	JoiningGroupDef             *PropertyDef[JoiningGroup]
+	NumericTypeDef              *PropertyDef[NumericType]
+	NumericValueDef             *PropertyDef[NumericValue]
+	IdentifierStatusDef         *PropertyDef[IdentifierStatus]
+	IdentifierTypeDef           *PropertyDef[IdentifierType]
 }
 
 type EvalContext struct {
@@ -101,11 +172,38 @@ type EvalContext struct {
 	GraphemeBreakPropMap        UniSetMap[GraphemeBreakProperty]
 	WordBreakPropMap            UniSetMap[WordBreakProperty]
 	SentenceBreakPropMap        UniSetMap[SentenceBreakProperty]
+	LineBreakMap                UniSetMap[LineBreak]
+	IndicSyllabicCategoryMap    UniSetMap[IndicSyllabicCategory]
+	IndicPositionalCategoryMap  UniSetMap[IndicPositionalCategory]
+	VerticalOrientationMap      UniSetMap[VerticalOrientation]
+	HangulSyllableTypeMap       UniSetMap[HangulSyllableType]
+	BidiClassMap                UniSetMap[BidiClass]
+	CombiningClassMap           UniSetMap[CombiningClass]
+	DecompositionTypeMap        UniSetMap[DecompositionType]
+	BidiPairedBracketTypeMap    UniSetMap[BidiPairedBracketType]
+	BidiMirroringMap            RuneMap // Bidi_Mirroring_Glyph
+	AgeMap                      UniSetMap[Age]
+	BlockMap                    UniSetMap[Block]
+	JoiningTypeMap              UniSetMap[JoiningType]
+	JoiningGroupMap             UniSetMap[JoiningGroup]
+	NumericTypeMap              UniSetMap[NumericType]
+	NumericValueMap             UniSetMap[NumericValue]
+	IdentifierStatusMap         UniSetMap[IdentifierStatus]
+	IdentifierTypeMap           UniSetMap[IdentifierType] // code point may have multiple identifier types
+	UnlistedMap                 map[string]*set.UniSet    // code points not listed in data files (values of @missing lines), keyed by property prefix
+	CharNames                   *CharNames
 	CaseFoldingMap              *CaseFoldMap
+	CaseMapping                 *CaseMapping // full case mapping (unconditional)
+	Normalizer                  *Normalizer
+	Confusables                 *Confusables
 	StringPropertyMap           StringPropertyMap
+	bindingCache                map[*Binding]*set.UniSet // for evaluated let-bindings
 }
 
 func NewEvalContext(data *UnicodeData) (*EvalContext, error) {
+	if data.UCDXML != "" {
+		return NewEvalContextFromUCDXML(data)
+	}
 	headers := DataHeaders{}
 	catMap, err := LoadGeneralCategoryMap(data.GeneralCategory, &headers)
 	if err != nil {
@@ -159,10 +257,94 @@ func NewEvalContext(data *UnicodeData) (*EvalContext, error) {
 	if err != nil {
 		return nil, err
 	}
+	lineBreakDef, lineBreakMap, err := LoadLineBreakMap(data.LineBreak, &headers)
+	if err != nil {
+		return nil, err
+	}
+	inscDef, inscMap, err := LoadPropertyMap[IndicSyllabicCategory](data.IndicSyllabicCategory, &headers)
+	if err != nil {
+		return nil, err
+	}
+	inpcDef, inpcMap, err := LoadPropertyMap[IndicPositionalCategory](data.IndicPositionalCategory, &headers)
+	if err != nil {
+		return nil, err
+	}
+	voDef, voMap, err := LoadPropertyMapWithDefault[VerticalOrientation](data.VerticalOrientation,
+		VerticalOrientationDefault, aliasMaps.VerticalOrientation(), &headers)
+	if err != nil {
+		return nil, err
+	}
+	hstDef, hstMap, err := LoadPropertyMapWithDefault[HangulSyllableType](data.HangulSyllableType,
+		HangulSyllableTypeDefault, aliasMaps.HangulSyllableType(), &headers)
+	if err != nil {
+		return nil, err
+	}
+	mirroringMap, err := LoadBidiMirroringMap(data.BidiMirroring, &headers)
+	if err != nil {
+		return nil, err
+	}
+	bptDef, bptMap, err := LoadBidiBracketsMap(data.BidiBrackets, &headers)
+	if err != nil {
+		return nil, err
+	}
 	caseFoldingMap, err := LoadCaseFoldingMap(data.CaseFolding, &headers)
 	if err != nil {
 		return nil, err
 	}
+	charData, err := LoadUnicodeData(data.UnicodeData, &headers)
+	if err != nil {
+		return nil, err
+	}
+	bcMap, err := LoadBidiClassMap(data.DerivedBidiClass, charData.BidiClassDef, aliasMaps.BidiClass(), &headers)
+	if err != nil {
+		return nil, err
+	}
+	err = LoadNameAliases(data.NameAliases, charData.Names, &headers)
+	if err != nil {
+		return nil, err
+	}
+	err = LoadSpecialCasing(data.SpecialCasing, charData.CaseMapping, &headers)
+	if err != nil {
+		return nil, err
+	}
+	ageDef, ageMap, err := LoadAgeMap(data.DerivedAge, &headers)
+	if err != nil {
+		return nil, err
+	}
+	blockDef, blockMap, err := LoadBlockMap(data.Blocks, aliasMaps.Block(), &headers)
+	if err != nil {
+		return nil, err
+	}
+	jtDef, jtMap, err := LoadPropertyMapWithDefault[JoiningType](data.DerivedJoiningType,
+		JoiningTypeDefault, aliasMaps.JoiningType(), &headers)
+	if err != nil {
+		return nil, err
+	}
+	jgDef, jgMap, err := LoadJoiningGroupMap(data.ArabicShaping, aliasMaps.JoiningGroup(), &headers)
+	if err != nil {
+		return nil, err
+	}
+	ntDef, ntMap, err := LoadNumericTypeMap(data.DerivedNumericType, aliasMaps.NumericType(), &headers)
+	if err != nil {
+		return nil, err
+	}
+	nvDef, nvMap, err := LoadNumericValueMap(data.DerivedNumericValues, &headers)
+	if err != nil {
+		return nil, err
+	}
+	idstDef, idstMap, err := LoadPropertyMapWithDefault[IdentifierStatus](data.IdentifierStatus,
+		IdentifierStatusDefault, nil, &headers)
+	if err != nil {
+		return nil, err
+	}
+	idtyDef, idtyMap, err := LoadIdentifierTypeMap(data.IdentifierType, &headers)
+	if err != nil {
+		return nil, err
+	}
+	confusables, err := LoadConfusables(data.Confusables, &headers)
+	if err != nil {
+		return nil, err
+	}
 	stringPropertyMap := make(StringPropertyMap)
 	err = LoadStringPropertyMap(data.EmojiSequences, &headers, stringPropertyMap)
 	if err != nil {
@@ -173,6 +355,14 @@ func NewEvalContext(data *UnicodeData) (*EvalContext, error) {
 		return nil, err
 	}
 
+	unlistedMap := map[string]*set.UniSet{
+		EastAsianWidthPrefix:    headers.Unlisted[data.EastAsianWidth],
+		ScriptPrefix:            headers.Unlisted[data.Scripts],
+		GraphemeBreakPropPrefix: headers.Unlisted[data.GraphemeBreakProperty],
+		WordBreakPropPrefix:     headers.Unlisted[data.WordBreakProperty],
+		SentenceBreakPropPrefix: headers.Unlisted[data.SentenceBreakProperty],
+	}
+
 	return &EvalContext{
 		Headers:        headers,
 		CateMap:        catMap,
@@ -188,6 +378,23 @@ func NewEvalContext(data *UnicodeData) (*EvalContext, error) {
 			GraphemeBreakPropDef:        graphemePropDef,
 			WordBreakPropDef:            wordPropDef,
 			SentenceBreakPropDef:        sentencePropDef,
+			LineBreakDef:                lineBreakDef,
+			IndicSyllabicCategoryDef:    inscDef,
+			IndicPositionalCategoryDef:  inpcDef,
+			VerticalOrientationDef:      voDef,
+			HangulSyllableTypeDef:       hstDef,
+			BidiClassDef:                charData.BidiClassDef,
+			CombiningClassDef:           charData.CombiningClassDef,
+			DecompositionTypeDef:        charData.DecompositionTypeDef,
+			BidiPairedBracketTypeDef:    bptDef,
+			AgeDef:                      ageDef,
+			BlockDef:                    blockDef,
+			JoiningTypeDef:              jtDef,
+			JoiningGroupDef:             jgDef,
+			NumericTypeDef:              ntDef,
+			NumericValueDef:             nvDef,
+			IdentifierStatusDef:         idstDef,
+			IdentifierTypeDef:           idtyDef,
 		},
 		ScriptMap:                   scriptMap,
 		ScriptXMap:                  scriptXMap,
@@ -199,43 +406,38 @@ func NewEvalContext(data *UnicodeData) (*EvalContext, error) {
 		GraphemeBreakPropMap:        graphemePropMap,
 		WordBreakPropMap:            wordPropMap,
 		SentenceBreakPropMap:        sentencePropMap,
+		LineBreakMap:                lineBreakMap,
+		IndicSyllabicCategoryMap:    inscMap,
+		IndicPositionalCategoryMap:  inpcMap,
+		VerticalOrientationMap:      voMap,
+		HangulSyllableTypeMap:       hstMap,
+		BidiClassMap:                bcMap,
+		CombiningClassMap:           charData.CombiningClassMap,
+		DecompositionTypeMap:        charData.DecompositionTypeMap,
+		BidiPairedBracketTypeMap:    bptMap,
+		BidiMirroringMap:            mirroringMap,
+		AgeMap:                      ageMap,
+		BlockMap:                    blockMap,
+		JoiningTypeMap:              jtMap,
+		JoiningGroupMap:             jgMap,
+		NumericTypeMap:              ntMap,
+		NumericValueMap:             nvMap,
+		IdentifierStatusMap:         idstMap,
+		IdentifierTypeMap:           idtyMap,
+		UnlistedMap:                 unlistedMap,
+		CharNames:                   charData.Names,
 		CaseFoldingMap:              caseFoldingMap,
+		CaseMapping:                 charData.CaseMapping,
+		Normalizer:                  charData.Normalizer,
+		Confusables:                 confusables,
 		StringPropertyMap:           stringPropertyMap,
 	}, nil
 }
 
-func (e *EvalContext) FillEawN() *set.UniSet {
-	eawSet := e.EawMap[EAW_N]
-	if eawSet != nil {
-		return eawSet
-	}
-	tmpSet := set.NewUniSetAll()
-	builder := set.UniSetBuilder{}
-	for eaw := range EachEastAsianWidth {
-		if eaw != EAW_N {
-			builder.AddSet(e.EawMap[eaw])
-		}
-	}
-	tmpSet.RemoveSet(new(builder.Build()))
-	e.EawMap[EAW_N] = &tmpSet
-	return e.EawMap[EAW_N]
-}
-
-func (e *EvalContext) FillScriptUnknown() *set.UniSet {
-	scriptSet := e.ScriptMap[e.DefRecord.ScriptDef.Unknown()]
-	if scriptSet != nil {
-		return scriptSet
-	}
-	tmpSet := set.NewUniSetAll()
-	builder := set.UniSetBuilder{}
-	for sc := range e.DefRecord.ScriptDef.EachScript {
-		if sc != e.DefRecord.ScriptDef.Unknown() {
-			builder.AddSet(e.ScriptMap[sc])
-		}
-	}
-	tmpSet.RemoveSet(new(builder.Build()))
-	e.ScriptMap[e.DefRecord.ScriptDef.Unknown()] = &tmpSet
-	return e.ScriptMap[e.DefRecord.ScriptDef.Unknown()]
+// MissingEntry default property values declared by `# @missing:` line
+type MissingEntry struct {
+	RuneRange set.RuneRange
+	Values    []string // such as [Other], [NFD_QC, Yes]
 }
 
 type DataLoader struct {
@@ -244,6 +446,7 @@ type DataLoader struct {
 	scanner *bufio.Scanner
 	lineno  int
 	header  DataHeader
+	missing []MissingEntry
 }
 
 func NewDataLoader(p string) (DataLoader, error) {
@@ -315,11 +518,80 @@ func parseEntry(line string, join bool) (runeRange set.RuneRange, property strin
 	return
 }
 
+func parseMissingEntry(line string) (MissingEntry, error) {
+	// line: 0000..10FFFF; Other
+	// line: 0000..10FFFF; NFD_QC; Yes
+	runeRange, _, err := parseEntry(line, true)
+	if err != nil {
+		return MissingEntry{}, err
+	}
+	ss := strings.Split(strings.TrimSpace(strings.Split(line, "#")[0]), ";")
+	if len(ss) < 2 {
+		return MissingEntry{}, fmt.Errorf("invalid @missing entry: %s", strings.TrimSpace(line))
+	}
+	entry := MissingEntry{RuneRange: runeRange}
+	for _, s := range ss[1:] {
+		entry.Values = append(entry.Values, strings.TrimSpace(s))
+	}
+	return entry, nil
+}
+
+// resolveMissing resolves `# @missing:` lines into default value sets of code points not contained in found.
+// only lines that have a single property value are applied, and later lines take precedence over earlier ones.
+// if there is no such line, all code points not contained in found are fallback (unless fallback is empty).
+// values are replaced with abbreviations defined in aliasMap (if aliasMap is not nil)
+func (d *DataLoader) resolveMissing(found *set.UniSet, fallback string, aliasMap *AliasMap) (names []string, setMap map[string]*set.UniSet) {
+	var entries []MissingEntry
+	for _, entry := range d.missing {
+		if len(entry.Values) == 1 && !strings.HasPrefix(entry.Values[0], "<") { // skip such as <none>, <script>
+			entries = append(entries, entry)
+		}
+	}
+	if len(entries) == 0 {
+		if fallback == "" {
+			return nil, nil
+		}
+		entries = append(entries, MissingEntry{
+			RuneRange: set.RuneRange{First: 0, Last: utf8.MaxRune},
+			Values:    []string{fallback},
+		})
+	}
+	toName := func(entry MissingEntry) string {
+		if aliasMap != nil {
+			if abbr := aliasMap.LookupAbbr(entry.Values[0]); abbr != "" {
+				return abbr
+			}
+		}
+		return entry.Values[0]
+	}
+
+	setMap = map[string]*set.UniSet{}
+	for _, entry := range entries {
+		if name := toName(entry); setMap[name] == nil {
+			names = append(names, name)
+			setMap[name] = &set.UniSet{}
+		}
+	}
+	claimed := found.Copy()
+	for _, entry := range slices.Backward(entries) {
+		builder := set.UniSetBuilder{}
+		builder.AddRange(entry.RuneRange)
+		uniSet := builder.Build()
+		uniSet.RemoveSet(&claimed)
+		setMap[toName(entry)].AddSet(&uniSet)
+		claimed.AddRange(entry.RuneRange)
+	}
+	return names, setMap
+}
+
 func (d *DataLoader) Load(callback func(string) error) error {
 	defer func(reader io.ReadCloser) {
 		_ = reader.Close()
 	}(d.file)
 	for lineno, line := range d.Next {
+		if lineno == 1 {
+			line = strings.TrimPrefix(line, "\uFEFF") // skip BOM (such as confusables.txt)
+		}
 		if lineno == 1 && strings.HasPrefix(line, "#") {
 			d.header.Filename = strings.TrimPrefix(line, "# ")
 			continue
@@ -328,6 +600,14 @@ func (d *DataLoader) Load(callback func(string) error) error {
 			d.header.Created = strings.TrimPrefix(line, "# ")
 			continue
 		}
+		if entry, ok := strings.CutPrefix(line, "# @missing:"); ok {
+			missing, err := parseMissingEntry(entry)
+			if err != nil {
+				return d.formatErr(err)
+			}
+			d.missing = append(d.missing, missing)
+			continue
+		}
 		if strings.HasPrefix(line, "#") || line == "" {
 			continue
 		}
@@ -386,12 +666,11 @@ func LoadGeneralCategoryMap(filename string, dbInfoList *DataHeaders) (setMap Un
 	return
 }
 
+// LoadEastAsianWidthMap load EastAsianWidth.txt. code points not listed in EastAsianWidth.txt are values of
+// @missing lines (such as W for CJK ideographs) or N
 func LoadEastAsianWidthMap(filename string, dbInfoList *DataHeaders) (setMap UniSetMap[EastAsianWidth], e error) {
 	builderMap := map[EastAsianWidth]*set.UniSetBuilder{}
 	for eaw := range EachEastAsianWidth {
-		if eaw == EAW_N {
-			continue // fill N later
-		}
 		builderMap[eaw] = &set.UniSetBuilder{}
 	}
 
@@ -405,9 +684,7 @@ func LoadEastAsianWidthMap(filename string, dbInfoList *DataHeaders) (setMap Uni
 		if err != nil {
 			return err
 		}
-		if eaw != EAW_N { // skip N (fill later)
-			builderMap[eaw].AddRange(runeRange)
-		}
+		builderMap[eaw].AddRange(runeRange)
 		return nil
 	})
 	if err != nil {
@@ -416,10 +693,21 @@ func LoadEastAsianWidthMap(filename string, dbInfoList *DataHeaders) (setMap Uni
 
 	// build
 	setMap = map[EastAsianWidth]*set.UniSet{}
+	found := set.UniSet{}
 	for cate, builder := range builderMap {
 		setMap[cate] = new(builder.Build())
+		found.AddSet(setMap[cate])
+	}
+	names, defaultMap := loader.resolveMissing(&found, EAW_N.String(), nil)
+	for _, name := range names {
+		eaw, err := ParseEastAsianWidth(name, nil)
+		if err != nil {
+			return nil, loader.formatErr(err)
+		}
+		setMap[eaw].AddSet(defaultMap[name])
 	}
 	dbInfoList.List = append(dbInfoList.List, loader.header)
+	dbInfoList.addUnlisted(filename, &found)
 	return
 }
 
@@ -462,19 +750,35 @@ func LoadScriptMap(filename string, aliasMap *AliasMap, dbInfoList *DataHeaders)
 		return nil, nil, err
 	}
 
+	// build
+	setMap = map[Script]*set.UniSet{}
+	found := set.UniSet{}
+	for cate, builder := range builderMap {
+		setMap[cate] = new(builder.Build())
+		found.AddSet(setMap[cate])
+	}
+
+	// fill unlisted code points with @missing values (Unknown)
+	names, defaultMap := loader.resolveMissing(&found, ScriptUnknown, nil)
+	for _, name := range names {
+		if _, ok := nameToScript[name]; !ok {
+			nameToScript[name] = Script(len(nameToScript))
+			setMap[nameToScript[name]] = &set.UniSet{}
+		}
+		setMap[nameToScript[name]].AddSet(defaultMap[name])
+	}
+
 	// fix-up
 	longs := make([]string, len(nameToScript))
 	for k, v := range nameToScript {
 		longs[v] = k
 	}
 	scriptDef := NewScriptDef(longs, aliasMap)
-
-	// build
-	setMap = map[Script]*set.UniSet{}
-	for cate, builder := range builderMap {
-		setMap[cate] = new(builder.Build())
+	if _, ok := setMap[scriptDef.Unknown()]; !ok {
+		setMap[scriptDef.Unknown()] = &set.UniSet{}
 	}
 	dbInfoList.List = append(dbInfoList.List, loader.header)
+	dbInfoList.addUnlisted(filename, &found)
 	return scriptDef, setMap, nil
 }
 
@@ -520,11 +824,9 @@ func LoadScriptXMap(filename string, def *ScriptDef, scriptSetMap UniSetMap[Scri
 	for script, uniSet := range scriptSetMap {
 		builder := set.UniSetBuilder{}
 		if script == common || script == inherited {
-			for r := range uniSet.Iter {
-				if !foundSet.Find(r) {
-					builder.Add(r)
-				}
-			}
+			tmpSet := uniSet.Copy()
+			tmpSet.RemoveSet(&foundSet)
+			builder.AddSet(&tmpSet)
 		} else {
 			builder.AddSet(uniSet)
 			if r, ok := builderMap[script]; ok {
@@ -537,7 +839,7 @@ func LoadScriptXMap(filename string, def *ScriptDef, scriptSetMap UniSetMap[Scri
 	return setMap, nil
 }
 
-func LoadPropertyMapWithJoin[T ~int](filename string, dbInfoList *DataHeaders, join bool) (def *PropertyDef[T], setMap UniSetMap[T], e error) {
+func loadPropertyMap[T ~int](filename string, join bool, fallback string, aliasMap *AliasMap, dbInfoList *DataHeaders) (def *PropertyDef[T], setMap UniSetMap[T], e error) {
 	builderMap := map[T]*set.UniSetBuilder{}
 	nameToProp := map[string]T{}
 
@@ -560,57 +862,890 @@ func LoadPropertyMapWithJoin[T ~int](filename string, dbInfoList *DataHeaders, j
 		return nil, nil, err
 	}
 
+	// build
+	setMap = map[T]*set.UniSet{}
+	found := set.UniSet{}
+	for cate, builder := range builderMap {
+		setMap[cate] = new(builder.Build())
+		found.AddSet(setMap[cate])
+	}
+
+	// fill unlisted code points with @missing values
+	names, defaultMap := loader.resolveMissing(&found, fallback, aliasMap)
+	for _, name := range names {
+		if _, ok := nameToProp[name]; !ok {
+			nameToProp[name] = T(len(nameToProp))
+			setMap[nameToProp[name]] = &set.UniSet{}
+		}
+		setMap[nameToProp[name]].AddSet(defaultMap[name])
+	}
+
 	// fix-up
 	longs := make([]string, len(nameToProp))
 	for k, v := range nameToProp {
 		longs[v] = k
 	}
 	propDef := NewPropertyDef[T](longs)
-
-	// build
-	setMap = map[T]*set.UniSet{}
-	for cate, builder := range builderMap {
-		setMap[cate] = new(builder.Build())
-	}
 	dbInfoList.List = append(dbInfoList.List, loader.header)
+	dbInfoList.addUnlisted(filename, &found)
 	return propDef, setMap, nil
 }
 
+// LoadPropertyMapWithJoin load property map. if join is true, multiple property fields are joined with `_`
+// (such as `InCB; Extend` to `InCB_Extend`). code points not listed in filename are values of @missing lines
+func LoadPropertyMapWithJoin[T ~int](filename string, dbInfoList *DataHeaders, join bool) (def *PropertyDef[T], setMap UniSetMap[T], e error) {
+	return loadPropertyMap[T](filename, join, "", nil, dbInfoList)
+}
+
 func LoadPropertyMap[T ~int](filename string, dbInfoList *DataHeaders) (def *PropertyDef[T], setMap UniSetMap[T], e error) {
 	return LoadPropertyMapWithJoin[T](filename, dbInfoList, false)
 }
 
-func LoadCaseFoldingMap(filename string, dbInfoList *DataHeaders) (*CaseFoldMap, error) {
+// LoadPropertyMapWithDefault load property map. code points not listed in filename are values of @missing lines
+// (long names are replaced with abbreviations defined in aliasMap).
+// if filename has no @missing line, they are defaultValue
+func LoadPropertyMapWithDefault[T ~int](filename string, defaultValue string, aliasMap *AliasMap, dbInfoList *DataHeaders) (def *PropertyDef[T], setMap UniSetMap[T], e error) {
+	def, setMap, err := loadPropertyMap[T](filename, false, defaultValue, aliasMap, dbInfoList)
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, ok := def.nameToProperty[defaultValue]; !ok { // always define default value
+		setMap[T(len(def.propertyToName))] = &set.UniSet{}
+		def = NewPropertyDef[T](append(def.propertyToName, defaultValue))
+	}
+	return def, setMap, nil
+}
+
+// LoadLineBreakMap load LineBreak.txt. code points not listed in LineBreak.txt are XX (Unknown)
+// or values of @missing lines
+func LoadLineBreakMap(filename string, dbInfoList *DataHeaders) (*PropertyDef[LineBreak], UniSetMap[LineBreak], error) {
+	return LoadPropertyMapWithDefault[LineBreak](filename, LineBreakUnknown, nil, dbInfoList)
+}
+
+// LoadBidiClassMap load DerivedBidiClass.txt. code points not listed are values of @missing lines
+// (such as R for Hebrew, AL for Arabic and BN for noncharacters) or L
+func LoadBidiClassMap(filename string, def *PropertyDef[BidiClass], aliasMap *AliasMap, dbInfoList *DataHeaders) (UniSetMap[BidiClass], error) {
+	builderMap := map[BidiClass]*set.UniSetBuilder{}
+	loader, err := NewDataLoader(filename)
+	if err != nil {
+		return nil, err
+	}
+	err = loader.LoadProperties(false, func(runeRange set.RuneRange, property string) error {
+		// line: 05D0..05EA    ; R  # Lo  [27] HEBREW LETTER ALEF..HEBREW LETTER TAV
+		bc, err := def.ParseWithAlias(property, aliasMap)
+		if err != nil {
+			return err
+		}
+		if _, ok := builderMap[bc]; !ok {
+			builderMap[bc] = &set.UniSetBuilder{}
+		}
+		builderMap[bc].AddRange(runeRange)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// build
+	setMap := map[BidiClass]*set.UniSet{}
+	found := set.UniSet{}
+	for bc, builder := range builderMap {
+		setMap[bc] = new(builder.Build())
+		found.AddSet(setMap[bc])
+	}
+
+	// fill unlisted code points with @missing values
+	names, defaultMap := loader.resolveMissing(&found, BidiClassDefault, aliasMap)
+	for _, name := range names {
+		bc, err := def.ParseWithAlias(name, aliasMap)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := setMap[bc]; !ok {
+			setMap[bc] = &set.UniSet{}
+		}
+		setMap[bc].AddSet(defaultMap[name])
+	}
+	dbInfoList.List = append(dbInfoList.List, loader.header)
+	return setMap, nil
+}
+
+// CharDataRecord properties loaded from UnicodeData.txt
+type CharDataRecord struct {
+	BidiClassDef         *PropertyDef[BidiClass]
+	BidiClassMap         UniSetMap[BidiClass]
+	CombiningClassDef    *PropertyDef[CombiningClass]
+	CombiningClassMap    UniSetMap[CombiningClass]
+	DecompositionTypeDef *PropertyDef[DecompositionType]
+	DecompositionTypeMap UniSetMap[DecompositionType]
+	Names                *CharNames
+	CaseMapping          *CaseMapping // simple case mapping
+	Normalizer           *Normalizer
+}
+
+func parseDecompositionType(decomposition string) (string, error) {
+	// decomposition: <compat> 0020 0308
+	// decomposition: 0041 0300
+	if decomposition == "" {
+		return "None", nil
+	}
+	if !strings.HasPrefix(decomposition, "<") {
+		return "Can", nil
+	}
+	end := strings.Index(decomposition, ">")
+	if end < 0 {
+		return "", fmt.Errorf("invalid decomposition: %s", decomposition)
+	}
+	tag := decomposition[1:end]
+	if abbr, ok := decompositionTags[tag]; ok {
+		return abbr, nil
+	}
+	return "", fmt.Errorf("unknown decomposition tag: %s", tag)
+}
+
+// charDataBuilder intermediate state of CharDataRecord (shared by UnicodeData.txt and UCDXML loader)
+type charDataBuilder struct {
+	bcDef         *PropertyDef[BidiClass]
+	dtDef         *PropertyDef[DecompositionType]
+	bcBuilderMap  map[BidiClass]*set.UniSetBuilder
+	cccBuilderMap map[int]*set.UniSetBuilder
+	dtBuilderMap  map[DecompositionType]*set.UniSetBuilder
+	names         *CharNames
+	caseMapping   *CaseMapping
+	normalizer    *Normalizer
+}
+
+func newCharDataBuilder() *charDataBuilder {
+	return &charDataBuilder{
+		bcDef:         NewPropertyDef[BidiClass](bidiClassNames),
+		dtDef:         NewPropertyDef[DecompositionType](decompositionTypeNames),
+		bcBuilderMap:  map[BidiClass]*set.UniSetBuilder{},
+		cccBuilderMap: map[int]*set.UniSetBuilder{},
+		dtBuilderMap:  map[DecompositionType]*set.UniSetBuilder{},
+		names:         NewCharNames(),
+		caseMapping:   NewCaseMapping(),
+		normalizer:    NewNormalizer(),
+	}
+}
+
+func (b *charDataBuilder) addBidiClass(runeRange set.RuneRange, s string) error {
+	bc, err := b.bcDef.Parse(s)
+	if err != nil {
+		return err
+	}
+	if _, ok := b.bcBuilderMap[bc]; !ok {
+		b.bcBuilderMap[bc] = &set.UniSetBuilder{}
+	}
+	b.bcBuilderMap[bc].AddRange(runeRange)
+	return nil
+}
+
+func (b *charDataBuilder) addCombiningClass(runeRange set.RuneRange, ccc int) {
+	if _, ok := b.cccBuilderMap[ccc]; !ok {
+		b.cccBuilderMap[ccc] = &set.UniSetBuilder{}
+	}
+	b.cccBuilderMap[ccc].AddRange(runeRange)
+}
+
+func (b *charDataBuilder) addDecompositionType(runeRange set.RuneRange, dtName string) error {
+	dt, err := b.dtDef.Parse(dtName)
+	if err != nil {
+		return err
+	}
+	if dt != b.dtNone() { // fill None later
+		if _, ok := b.dtBuilderMap[dt]; !ok {
+			b.dtBuilderMap[dt] = &set.UniSetBuilder{}
+		}
+		b.dtBuilderMap[dt].AddRange(runeRange)
+	}
+	return nil
+}
+
+func (b *charDataBuilder) dtNone() DecompositionType {
+	dtNone, _ := b.dtDef.Parse("None")
+	return dtNone
+}
+
+func (b *charDataBuilder) build() *CharDataRecord {
+	record := &CharDataRecord{
+		BidiClassDef:         b.bcDef,
+		BidiClassMap:         map[BidiClass]*set.UniSet{},
+		DecompositionTypeDef: b.dtDef,
+		DecompositionTypeMap: map[DecompositionType]*set.UniSet{},
+		CombiningClassMap:    map[CombiningClass]*set.UniSet{},
+		Names:                b.names,
+		CaseMapping:          b.caseMapping,
+		Normalizer:           b.normalizer,
+	}
+	bcFound := set.UniSetBuilder{}
+	for bc, builder := range b.bcBuilderMap {
+		record.BidiClassMap[bc] = new(builder.Build())
+		bcFound.AddSet(record.BidiClassMap[bc])
+	}
+	bcFoundSet := bcFound.Build() // unlisted code points are L
+	bcDefault, _ := b.bcDef.Parse(BidiClassDefault)
+	if _, ok := record.BidiClassMap[bcDefault]; !ok {
+		record.BidiClassMap[bcDefault] = &set.UniSet{}
+	}
+	record.BidiClassMap[bcDefault].AddSet(new(bcFoundSet.Complement()))
+	dtFound := set.UniSetBuilder{}
+	for dt, builder := range b.dtBuilderMap {
+		record.DecompositionTypeMap[dt] = new(builder.Build())
+		dtFound.AddSet(record.DecompositionTypeMap[dt])
+	}
+	dtFoundSet := dtFound.Build() // unlisted code points are None
+	record.DecompositionTypeMap[b.dtNone()] = new(dtFoundSet.Complement())
+
+	// Canonical_Combining_Class values are sorted numerically. unlisted code points are 0 (Not_Reordered)
+	if _, ok := b.cccBuilderMap[0]; !ok {
+		b.cccBuilderMap[0] = &set.UniSetBuilder{}
+	}
+	cccValues := slices.Sorted(maps.Keys(b.cccBuilderMap)) // 0 is always first
+	cccNames := make([]string, 0, len(cccValues))
+	cccFound := set.UniSetBuilder{}
+	for i, ccc := range cccValues {
+		cccNames = append(cccNames, strconv.Itoa(ccc))
+		if ccc != 0 {
+			record.CombiningClassMap[CombiningClass(i)] = new(b.cccBuilderMap[ccc].Build())
+			cccFound.AddSet(record.CombiningClassMap[CombiningClass(i)])
+		}
+	}
+	cccFoundSet := cccFound.Build()
+	record.CombiningClassMap[CombiningClass(0)] = new(cccFoundSet.Complement())
+	record.CombiningClassDef = NewPropertyDef[CombiningClass](cccNames)
+	return record
+}
+
+func LoadUnicodeData(filename string, dbInfoList *DataHeaders) (*CharDataRecord, error) {
+	builder := newCharDataBuilder()
+	names := builder.names
+	caseMapping := builder.caseMapping
+	normalizer := builder.normalizer
+	rangeFirst := rune(-1) // for <..., First>
+
+	// load
 	loader, err := NewDataLoader(filename)
 	if err != nil {
 		return nil, err
 	}
-	var foldPairs [][2]rune
 	err = loader.Load(func(line string) error {
+		// line: 0041;LATIN CAPITAL LETTER A;Lu;0;L;;;;;N;;;;0061;
+		// line: 4E00;<CJK Ideograph, First>;Lo;0;L;;;;;N;;;;;
 		ss := strings.Split(line, ";")
-		if len(ss) != 4 {
-			return fmt.Errorf("invalid case folding map: %s", line)
+		if len(ss) != 15 {
+			return fmt.Errorf("invalid unicode data entry: %s", line)
 		}
-		before := strings.TrimSpace(ss[0])
-		t := strings.TrimSpace(ss[1])
-		after := strings.TrimSpace(ss[2])
-		if t != "C" && t != "S" {
-			return nil
+		r, err := set.ParseRune(ss[0])
+		if err != nil {
+			return err
 		}
-		beforeRune, err := set.ParseRune(before)
+		runeRange := set.RuneRange{First: r, Last: r}
+		name := ss[1]
+		switch {
+		case strings.HasSuffix(name, ", First>"):
+			rangeFirst = r
+			return nil // add properties at <..., Last>
+		case strings.HasSuffix(name, ", Last>"):
+			if rangeFirst < 0 {
+				return fmt.Errorf("missing range first: %s", name)
+			}
+			runeRange.First = rangeFirst
+			rangeFirst = -1
+			names.AddRange(runeRange, strings.TrimSuffix(strings.TrimPrefix(name, "<"), ", Last>"))
+		default:
+			names.Add(r, name)
+		}
+
+		ccc, err := strconv.Atoi(ss[3])
 		if err != nil {
+			return fmt.Errorf("invalid canonical combining class: %s", ss[3])
+		}
+		builder.addCombiningClass(runeRange, ccc)
+		normalizer.SetCombiningClass(r, ccc)
+
+		if err := builder.addBidiClass(runeRange, ss[4]); err != nil {
 			return err
 		}
-		afterRune, err := set.ParseRune(after)
+
+		dtName, err := parseDecompositionType(ss[5])
 		if err != nil {
 			return err
 		}
-		//caseFoldingMap[beforeRune] = afterRune
-		foldPairs = append(foldPairs, [2]rune{beforeRune, afterRune})
-		return nil
-	})
-	dbInfoList.List = append(dbInfoList.List, loader.header)
-	caseFoldingMap := NewCaseFoldMap(foldPairs)
+		if isHangulSyllable(runeRange) { // no decomposition field, but decomposed algorithmically (Canonical)
+			dtName = "Can"
+		}
+		if err := builder.addDecompositionType(runeRange, dtName); err != nil {
+			return err
+		}
+		if dtName == "Can" && ss[5] != "" {
+			decomposition, err := parseCodePoints(ss[5])
+			if err != nil {
+				return err
+			}
+			normalizer.AddDecomposition(r, decomposition)
+		}
+
+		// simple case mapping (no case mapping in named ranges)
+		upper, err := parseCodePoints(ss[12])
+		if err != nil {
+			return err
+		}
+		lower, err := parseCodePoints(ss[13])
+		if err != nil {
+			return err
+		}
+		title, err := parseCodePoints(ss[14])
+		if err != nil {
+			return err
+		}
+		if title == nil { // if title case mapping is empty, equivalent to uppercase mapping
+			title = upper
+		}
+		if upper != nil {
+			caseMapping.SetUpper(r, upper)
+		}
+		if lower != nil {
+			caseMapping.SetLower(r, lower)
+		}
+		if title != nil {
+			caseMapping.SetTitle(r, title)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	record := builder.build()
+	if loader.header.Filename == "" { // UnicodeData.txt does not have header
+		loader.header.Filename = loader.name
+	}
+	dbInfoList.List = append(dbInfoList.List, loader.header)
+	return record, nil
+}
+
+// LoadAgeMap load DerivedAge.txt. ages are sorted by version and unlisted code points are NA (Unassigned)
+func LoadAgeMap(filename string, dbInfoList *DataHeaders) (*PropertyDef[Age], UniSetMap[Age], error) {
+	builderMap := map[string]*set.UniSetBuilder{}
+
+	// load
+	loader, err := NewDataLoader(filename)
+	if err != nil {
+		return nil, nil, err
+	}
+	err = loader.LoadProperties(false, func(runeRange set.RuneRange, property string) error {
+		if _, ok := parseVersion(property); !ok {
+			return fmt.Errorf("invalid version: %s", property)
+		}
+		if _, ok := builderMap[property]; !ok {
+			builderMap[property] = &set.UniSetBuilder{}
+		}
+		builderMap[property].AddRange(runeRange)
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// fix-up
+	versions := slices.SortedFunc(maps.Keys(builderMap), func(x, y string) int {
+		vx, _ := parseVersion(x)
+		vy, _ := parseVersion(y)
+		return slices.Compare(vx, vy)
+	})
+	ageDef := NewPropertyDef[Age](append(slices.Clone(versions), AgeUnassigned))
+
+	// build
+	setMap := map[Age]*set.UniSet{}
+	assigned := set.UniSetBuilder{}
+	for i, version := range versions {
+		setMap[Age(i)] = new(builderMap[version].Build())
+		assigned.AddSet(setMap[Age(i)])
+	}
+	assignedSet := assigned.Build()
+	setMap[Age(len(versions))] = new(assignedSet.Complement())
+	dbInfoList.List = append(dbInfoList.List, loader.header)
+	return ageDef, setMap, nil
+}
+
+// LoadBlockMap load Blocks.txt. block names are replaced with abbreviations defined in PropertyValueAliases.txt
+// and unlisted code points are No_Block
+func LoadBlockMap(filename string, aliasMap *AliasMap, dbInfoList *DataHeaders) (*PropertyDef[Block], UniSetMap[Block], error) {
+	toAbbr := func(name string) string {
+		if abbr := aliasMap.LookupAbbrLoose(name); abbr != "" {
+			return abbr
+		}
+		return strings.NewReplacer(" ", "_", "-", "_").Replace(name) // such as `Basic Latin` to `Basic_Latin`
+	}
+	var names []string
+	setMap := map[Block]*set.UniSet{}
+	found := set.UniSetBuilder{}
+
+	// load
+	loader, err := NewDataLoader(filename)
+	if err != nil {
+		return nil, nil, err
+	}
+	err = loader.LoadProperties(false, func(runeRange set.RuneRange, property string) error {
+		// each block is a single range
+		block := Block(len(names))
+		names = append(names, toAbbr(property))
+		builder := set.UniSetBuilder{}
+		builder.AddRange(runeRange)
+		setMap[block] = new(builder.Build())
+		found.AddRange(runeRange)
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// build
+	foundSet := found.Build()
+	setMap[Block(len(names))] = new(foundSet.Complement())
+	names = append(names, toAbbr(BlockNoBlock))
+	dbInfoList.List = append(dbInfoList.List, loader.header)
+	return NewPropertyDef[Block](names), setMap, nil
+}
+
+func LoadNameAliases(filename string, names *CharNames, dbInfoList *DataHeaders) error {
+	loader, err := NewDataLoader(filename)
+	if err != nil {
+		return err
+	}
+	err = loader.Load(func(line string) error {
+		// line: 0000;NULL;control
+		ss := strings.Split(line, ";")
+		if len(ss) != 3 {
+			return fmt.Errorf("invalid name alias entry: %s", line)
+		}
+		r, err := set.ParseRune(ss[0])
+		if err != nil {
+			return err
+		}
+		names.AddAlias(r, ss[1])
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	dbInfoList.List = append(dbInfoList.List, loader.header)
+	return nil
+}
+
+// RuneMap code point to code point mapping
+type RuneMap = map[rune]rune
+
+func LoadBidiMirroringMap(filename string, dbInfoList *DataHeaders) (RuneMap, error) {
+	mirroringMap := RuneMap{}
+	loader, err := NewDataLoader(filename)
+	if err != nil {
+		return nil, err
+	}
+	err = loader.Load(func(line string) error {
+		// line: 0028; 0029 # LEFT PARENTHESIS
+		ss := strings.Split(strings.Split(line, "#")[0], ";")
+		if len(ss) != 2 {
+			return fmt.Errorf("invalid bidi mirroring entry: %s", line)
+		}
+		r, err := set.ParseRune(strings.TrimSpace(ss[0]))
+		if err != nil {
+			return err
+		}
+		mirrored, err := set.ParseRune(strings.TrimSpace(ss[1]))
+		if err != nil {
+			return err
+		}
+		mirroringMap[r] = mirrored
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	dbInfoList.List = append(dbInfoList.List, loader.header)
+	return mirroringMap, nil
+}
+
+// LoadBidiBracketsMap load Bidi_Paired_Bracket_Type from BidiBrackets.txt. unlisted code points are n (None)
+func LoadBidiBracketsMap(filename string, dbInfoList *DataHeaders) (*PropertyDef[BidiPairedBracketType], UniSetMap[BidiPairedBracketType], error) {
+	def := NewPropertyDef[BidiPairedBracketType](bidiPairedBracketTypeNames)
+	builderMap := map[BidiPairedBracketType]*set.UniSetBuilder{}
+	loader, err := NewDataLoader(filename)
+	if err != nil {
+		return nil, nil, err
+	}
+	err = loader.Load(func(line string) error {
+		// line: 0028; 0029; o # LEFT PARENTHESIS
+		ss := strings.Split(strings.Split(line, "#")[0], ";")
+		if len(ss) != 3 {
+			return fmt.Errorf("invalid bidi brackets entry: %s", line)
+		}
+		r, err := set.ParseRune(strings.TrimSpace(ss[0]))
+		if err != nil {
+			return err
+		}
+		bpt, err := def.Parse(strings.TrimSpace(ss[2]))
+		if err != nil {
+			return err
+		}
+		if _, ok := builderMap[bpt]; !ok {
+			builderMap[bpt] = &set.UniSetBuilder{}
+		}
+		builderMap[bpt].Add(r)
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// build
+	setMap := map[BidiPairedBracketType]*set.UniSet{}
+	none, _ := def.Parse(BidiPairedBracketTypeNone)
+	found := set.UniSetBuilder{}
+	for bpt, builder := range builderMap {
+		if bpt != none {
+			setMap[bpt] = new(builder.Build())
+			found.AddSet(setMap[bpt])
+		}
+	}
+	foundSet := found.Build()
+	setMap[none] = new(foundSet.Complement())
+	dbInfoList.List = append(dbInfoList.List, loader.header)
+	return def, setMap, nil
+}
+
+// LoadJoiningGroupMap load Joining_Group from ArabicShaping.txt. names (such as `AFRICAN FEH`) are converted to
+// aliases defined in PropertyValueAliases.txt. code points not listed are No_Joining_Group
+func LoadJoiningGroupMap(filename string, aliasMap *AliasMap, dbInfoList *DataHeaders) (*PropertyDef[JoiningGroup], UniSetMap[JoiningGroup], error) {
+	toAbbr := func(name string) string {
+		if abbr := aliasMap.LookupAbbrLoose(name); abbr != "" {
+			return abbr
+		}
+		return strings.ReplaceAll(name, " ", "_")
+	}
+	builderMap := map[string]*set.UniSetBuilder{}
+	var names []string
+	loader, err := NewDataLoader(filename)
+	if err != nil {
+		return nil, nil, err
+	}
+	err = loader.Load(func(line string) error {
+		// line: 0622; ALEF WITH MADDA ABOVE; R; ALEF
+		ss := strings.Split(strings.Split(line, "#")[0], ";")
+		if len(ss) != 4 {
+			return fmt.Errorf("invalid arabic shaping entry: %s", line)
+		}
+		r, err := set.ParseRune(strings.TrimSpace(ss[0]))
+		if err != nil {
+			return err
+		}
+		name := toAbbr(strings.TrimSpace(ss[3]))
+		if _, ok := builderMap[name]; !ok {
+			builderMap[name] = &set.UniSetBuilder{}
+			names = append(names, name)
+		}
+		builderMap[name].Add(r)
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// build
+	defaultName := toAbbr(JoiningGroupDefault)
+	if _, ok := builderMap[defaultName]; !ok {
+		builderMap[defaultName] = &set.UniSetBuilder{}
+		names = append(names, defaultName)
+	}
+	setMap := map[JoiningGroup]*set.UniSet{}
+	found := set.UniSetBuilder{}
+	for i, name := range names {
+		setMap[JoiningGroup(i)] = new(builderMap[name].Build())
+		found.AddSet(setMap[JoiningGroup(i)])
+	}
+	foundSet := found.Build()
+	noJoiningGroup := JoiningGroup(slices.Index(names, defaultName))
+	setMap[noJoiningGroup].AddSet(new(foundSet.Complement()))
+	dbInfoList.List = append(dbInfoList.List, loader.header)
+	return NewPropertyDef[JoiningGroup](names), setMap, nil
+}
+
+// LoadNumericTypeMap load DerivedNumericType.txt. long names (such as `Decimal`) are converted to abbreviations.
+// code points not listed are None
+func LoadNumericTypeMap(filename string, aliasMap *AliasMap, dbInfoList *DataHeaders) (*PropertyDef[NumericType], UniSetMap[NumericType], error) {
+	def := NewPropertyDef[NumericType](numericTypeNames)
+	builderMap := map[NumericType]*set.UniSetBuilder{}
+	loader, err := NewDataLoader(filename)
+	if err != nil {
+		return nil, nil, err
+	}
+	err = loader.LoadProperties(false, func(runeRange set.RuneRange, property string) error {
+		// line: 0030..0039    ; Decimal # Nd  [10] DIGIT ZERO..DIGIT NINE
+		nt, err := def.ParseWithAlias(property, aliasMap)
+		if err != nil {
+			return err
+		}
+		if _, ok := builderMap[nt]; !ok {
+			builderMap[nt] = &set.UniSetBuilder{}
+		}
+		builderMap[nt].AddRange(runeRange)
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// build
+	setMap := map[NumericType]*set.UniSet{}
+	none, _ := def.Parse(NumericTypeNone)
+	found := set.UniSetBuilder{}
+	for nt, builder := range builderMap {
+		if nt != none {
+			setMap[nt] = new(builder.Build())
+			found.AddSet(setMap[nt])
+		}
+	}
+	foundSet := found.Build()
+	setMap[none] = new(foundSet.Complement())
+	dbInfoList.List = append(dbInfoList.List, loader.header)
+	return def, setMap, nil
+}
+
+// LoadNumericValueMap load DerivedNumericValues.txt. numeric values are represented in rational form
+// (such as `5`, `1/2`) and sorted in numerical order. code points not listed are NaN
+func LoadNumericValueMap(filename string, dbInfoList *DataHeaders) (*PropertyDef[NumericValue], UniSetMap[NumericValue], error) {
+	builderMap := map[string]*set.UniSetBuilder{}
+	values := map[string]float64{}
+	loader, err := NewDataLoader(filename)
+	if err != nil {
+		return nil, nil, err
+	}
+	err = loader.Load(func(line string) error {
+		// line: 0F33          ; -0.5 ; ; -1/2 # No       TIBETAN DIGIT HALF ZERO
+		runeRange, _, err := parseEntry(line, false)
+		if err != nil {
+			return err
+		}
+		ss := strings.Split(strings.Split(line, "#")[0], ";")
+		if len(ss) != 4 {
+			return fmt.Errorf("invalid numeric value entry: %s", line)
+		}
+		value := strings.TrimSpace(ss[3])
+		if _, ok := builderMap[value]; !ok {
+			v, err := parseRational(value)
+			if err != nil {
+				return err
+			}
+			values[value] = v
+			builderMap[value] = &set.UniSetBuilder{}
+		}
+		builderMap[value].AddRange(runeRange)
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// build
+	names := slices.SortedFunc(maps.Keys(values), func(x, y string) int {
+		return cmp.Compare(values[x], values[y])
+	})
+	setMap := map[NumericValue]*set.UniSet{}
+	found := set.UniSetBuilder{}
+	for i, name := range names {
+		setMap[NumericValue(i)] = new(builderMap[name].Build())
+		found.AddSet(setMap[NumericValue(i)])
+	}
+	foundSet := found.Build()
+	setMap[NumericValue(len(names))] = new(foundSet.Complement())
+	names = append(names, NumericValueNaN)
+	dbInfoList.List = append(dbInfoList.List, loader.header)
+	return NewExactPropertyDef[NumericValue](names), setMap, nil
+}
+
+// LoadIdentifierTypeMap load IdentifierType.txt. each entry may have multiple space separated types.
+// code points not listed are Not_Character
+func LoadIdentifierTypeMap(filename string, dbInfoList *DataHeaders) (*PropertyDef[IdentifierType], UniSetMap[IdentifierType], error) {
+	builderMap := map[IdentifierType]*set.UniSetBuilder{}
+	var names []string
+	nameToProp := map[string]IdentifierType{}
+	loader, err := NewDataLoader(filename)
+	if err != nil {
+		return nil, nil, err
+	}
+	err = loader.LoadProperties(false, func(runeRange set.RuneRange, property string) error {
+		// line: 00B7          ; Exclusion Not_XID              # 1.1        MIDDLE DOT
+		for _, name := range strings.Fields(property) {
+			if _, ok := nameToProp[name]; !ok {
+				nameToProp[name] = IdentifierType(len(names))
+				names = append(names, name)
+				builderMap[nameToProp[name]] = &set.UniSetBuilder{}
+			}
+			builderMap[nameToProp[name]].AddRange(runeRange)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// build
+	if _, ok := nameToProp[IdentifierTypeDefault]; !ok {
+		nameToProp[IdentifierTypeDefault] = IdentifierType(len(names))
+		names = append(names, IdentifierTypeDefault)
+		builderMap[nameToProp[IdentifierTypeDefault]] = &set.UniSetBuilder{}
+	}
+	setMap := map[IdentifierType]*set.UniSet{}
+	found := set.UniSetBuilder{}
+	for idty, builder := range builderMap {
+		setMap[idty] = new(builder.Build())
+		found.AddSet(setMap[idty])
+	}
+	foundSet := found.Build()
+	notCharacter := nameToProp[IdentifierTypeDefault]
+	setMap[notCharacter].AddSet(new(foundSet.Complement()))
+	dbInfoList.List = append(dbInfoList.List, loader.header)
+	return NewPropertyDef[IdentifierType](names), setMap, nil
+}
+
+// LoadConfusables load prototype mappings from confusables.txt
+func LoadConfusables(filename string, dbInfoList *DataHeaders) (*Confusables, error) {
+	loader, err := NewDataLoader(filename)
+	if err != nil {
+		return nil, err
+	}
+	prototypes := RuneStringMap{}
+	err = loader.Load(func(line string) error {
+		// line: 05AD ;	0596 ;	MA	# ( ֭ → ֖ ) HEBREW ACCENT DEHI → HEBREW ACCENT TIPEHA	#
+		ss := strings.Split(strings.Split(line, "#")[0], ";")
+		if len(ss) != 3 {
+			return fmt.Errorf("invalid confusable entry: %s", line)
+		}
+		r, err := set.ParseRune(strings.TrimSpace(ss[0]))
+		if err != nil {
+			return err
+		}
+		prototype, err := parseCodePoints(ss[1])
+		if err != nil {
+			return err
+		}
+		if len(prototype) == 0 {
+			return fmt.Errorf("invalid confusable entry: %s", line)
+		}
+		prototypes[r] = prototype
+		return nil
+	})
+	dbInfoList.List = append(dbInfoList.List, loader.header)
+	return NewConfusables(prototypes), err
+}
+
+// parseCodePoints parse space separated code points such as `0053 0053`. if empty, return nil
+func parseCodePoints(mapping string) ([]rune, error) {
+	var runes []rune
+	for _, code := range strings.Fields(mapping) {
+		r, err := set.ParseRune(code)
+		if err != nil {
+			return nil, err
+		}
+		runes = append(runes, r)
+	}
+	return runes, nil
+}
+
+// LoadSpecialCasing load unconditional case mappings from SpecialCasing.txt and override simple case mapping.
+// conditional (language-sensitive or context-sensitive) mappings are ignored
+func LoadSpecialCasing(filename string, caseMapping *CaseMapping, dbInfoList *DataHeaders) error {
+	loader, err := NewDataLoader(filename)
+	if err != nil {
+		return err
+	}
+	err = loader.Load(func(line string) error {
+		// line: 00DF; 00DF; 0053 0073; 0053 0053; # LATIN SMALL LETTER SHARP S
+		// line: 03A3; 03C2; 03A3; 03A3; Final_Sigma; # GREEK CAPITAL LETTER SIGMA
+		line = strings.Split(line, "#")[0]
+		ss := strings.Split(line, ";")
+		if len(ss) < 4 {
+			return fmt.Errorf("invalid special casing entry: %s", line)
+		}
+		if len(ss) > 4 && strings.TrimSpace(ss[4]) != "" { // has condition
+			return nil
+		}
+		r, err := set.ParseRune(strings.TrimSpace(ss[0]))
+		if err != nil {
+			return err
+		}
+		lower, err := parseCodePoints(ss[1])
+		if err != nil {
+			return err
+		}
+		title, err := parseCodePoints(ss[2])
+		if err != nil {
+			return err
+		}
+		upper, err := parseCodePoints(ss[3])
+		if err != nil {
+			return err
+		}
+		caseMapping.SetLower(r, lower)
+		caseMapping.SetTitle(r, title)
+		caseMapping.SetUpper(r, upper)
+		return nil
+	})
+	dbInfoList.List = append(dbInfoList.List, loader.header)
+	return err
+}
+
+func LoadCaseFoldingMap(filename string, dbInfoList *DataHeaders) (*CaseFoldMap, error) {
+	loader, err := NewDataLoader(filename)
+	if err != nil {
+		return nil, err
+	}
+	var foldPairs [][2]rune
+	fullFolds := RuneStringMap{}
+	var turkicPairs [][2]rune
+	err = loader.Load(func(line string) error {
+		ss := strings.Split(line, ";")
+		if len(ss) != 4 {
+			return fmt.Errorf("invalid case folding map: %s", line)
+		}
+		before := strings.TrimSpace(ss[0])
+		t := strings.TrimSpace(ss[1])
+		after := strings.TrimSpace(ss[2])
+		if t != "C" && t != "S" && t != "F" && t != "T" {
+			return nil
+		}
+		beforeRune, err := set.ParseRune(before)
+		if err != nil {
+			return err
+		}
+		if t == "F" {
+			afterRunes, err := parseCodePoints(after)
+			if err != nil {
+				return err
+			}
+			fullFolds[beforeRune] = afterRunes
+			return nil
+		}
+		afterRune, err := set.ParseRune(after)
+		if err != nil {
+			return err
+		}
+		if t == "T" {
+			turkicPairs = append(turkicPairs, [2]rune{beforeRune, afterRune})
+			return nil
+		}
+		//caseFoldingMap[beforeRune] = afterRune
+		foldPairs = append(foldPairs, [2]rune{beforeRune, afterRune})
+		return nil
+	})
+	dbInfoList.List = append(dbInfoList.List, loader.header)
+	caseFoldingMap := NewCaseFoldMap(foldPairs)
+	for r, runes := range fullFolds {
+		caseFoldingMap.AddFull(r, runes)
+	}
+	for _, pair := range turkicPairs {
+		caseFoldingMap.AddTurkic(pair[0], pair[1])
+	}
 	return caseFoldingMap, err
 }
 
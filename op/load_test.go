@@ -0,0 +1,595 @@
+package op
+
+import (
+	"testing"
+
+	"github.com/sekiguchi-nagisa/guniset/set"
+	"github.com/stretchr/testify/assert"
+)
+
+const testUnicodeData = `0009;<control>;Cc;0;S;;;;;N;CHARACTER TABULATION;;;;
+0041;LATIN CAPITAL LETTER A;Lu;0;L;;;;;N;;;;0061;
+00A0;NO-BREAK SPACE;Zs;0;CS;<noBreak> 0020;;;;N;NON-BREAKING SPACE;;;;
+00C0;LATIN CAPITAL LETTER A WITH GRAVE;Lu;0;L;0041 0300;;;;N;LATIN CAPITAL LETTER A GRAVE;;;00E0;
+0300;COMBINING GRAVE ACCENT;Mn;230;NSM;;;;;N;NON-SPACING GRAVE;;;;
+0316;COMBINING GRAVE ACCENT BELOW;Mn;220;NSM;;;;;N;NON-SPACING GRAVE BELOW;;;;
+0627;ARABIC LETTER ALEF;Lo;0;AL;;;;;N;;;;;
+3400;<CJK Ideograph Extension A, First>;Lo;0;L;;;;;N;;;;;
+4DBF;<CJK Ideograph Extension A, Last>;Lo;0;L;;;;;N;;;;;
+`
+
+func TestLoadUnicodeData(t *testing.T) {
+	path := writeFile(t, t.TempDir(), "UnicodeData.txt", testUnicodeData)
+	headers := DataHeaders{}
+	record, err := LoadUnicodeData(path, &headers)
+	assert.Nil(t, err)
+	assert.Equal(t, []DataHeader{{Filename: "UnicodeData.txt"}}, headers.List)
+
+	// name
+	name, ok := record.Names.Lookup(0x41)
+	assert.True(t, ok)
+	assert.Equal(t, "LATIN CAPITAL LETTER A", name)
+	name, ok = record.Names.Lookup(0x3500)
+	assert.True(t, ok)
+	assert.Equal(t, "CJK UNIFIED IDEOGRAPH-3500", name)
+	_, ok = record.Names.Lookup(0x42)
+	assert.False(t, ok)
+	name, ok = record.Names.Lookup(0x09)
+	assert.True(t, ok)
+	assert.Equal(t, "<control>", name)
+	for _, name := range record.Names.Each {
+		assert.NotEqual(t, "<control>", name)
+	}
+
+	// bidi class
+	bc, err := record.BidiClassDef.Parse("L")
+	assert.Nil(t, err)
+	assert.Equal(t, "{0x0000..0x0008,0x000a..0x009f,0x00a1..0x02ff,0x0301..0x0315,0x0317..0x0626,0x0628..0x10ffff}",
+		record.BidiClassMap[bc].String()) // unlisted code points are L
+	bc, err = record.BidiClassDef.Parse("NSM")
+	assert.Nil(t, err)
+	assert.Equal(t, "{0x0300..0x0300,0x0316..0x0316}", record.BidiClassMap[bc].String())
+
+	// combining class
+	assert.Equal(t, []string{"0", "220", "230"}, record.CombiningClassDef.propertyToName)
+	ccc, err := record.CombiningClassDef.Parse("230")
+	assert.Nil(t, err)
+	assert.Equal(t, "{0x0300..0x0300}", record.CombiningClassMap[ccc].String())
+	ccc, err = record.CombiningClassDef.Parse("0")
+	assert.Nil(t, err)
+	assert.Equal(t, "{0x0000..0x02ff,0x0301..0x0315,0x0317..0x10ffff}", record.CombiningClassMap[ccc].String())
+
+	// decomposition type
+	dt, err := record.DecompositionTypeDef.Parse("Can")
+	assert.Nil(t, err)
+	assert.Equal(t, "{0x00c0..0x00c0}", record.DecompositionTypeMap[dt].String())
+	dt, err = record.DecompositionTypeDef.Parse("Nb")
+	assert.Nil(t, err)
+	assert.Equal(t, "{0x00a0..0x00a0}", record.DecompositionTypeMap[dt].String())
+	dt, err = record.DecompositionTypeDef.Parse("None")
+	assert.Nil(t, err)
+	assert.Equal(t, "{0x0000..0x009f,0x00a1..0x00bf,0x00c1..0x10ffff}", record.DecompositionTypeMap[dt].String())
+
+	// Hangul syllables are Canonical (no decomposition field)
+	path = writeFile(t, t.TempDir(), "UnicodeData.txt", testUnicodeData+
+		"AC00;<Hangul Syllable, First>;Lo;0;L;;;;;N;;;;;\n"+
+		"D7A3;<Hangul Syllable, Last>;Lo;0;L;;;;;N;;;;;\n")
+	record, err = LoadUnicodeData(path, &headers)
+	assert.Nil(t, err)
+	dt, err = record.DecompositionTypeDef.Parse("Can")
+	assert.Nil(t, err)
+	assert.Equal(t, "{0x00c0..0x00c0,0xac00..0xd7a3}", record.DecompositionTypeMap[dt].String())
+	dt, err = record.DecompositionTypeDef.Parse("None")
+	assert.Nil(t, err)
+	assert.Equal(t, "{0x0000..0x009f,0x00a1..0x00bf,0x00c1..0xabff,0xd7a4..0x10ffff}", record.DecompositionTypeMap[dt].String())
+	assert.Equal(t, []rune{0x1100, 0x1161}, record.Normalizer.NFD([]rune{0xAC00}))
+
+	// invalid
+	path = writeFile(t, t.TempDir(), "UnicodeData.txt", "4DBF;<CJK Ideograph Extension A, Last>;Lo;0;L;;;;;N;;;;;\n")
+	_, err = LoadUnicodeData(path, &headers)
+	assert.NotNil(t, err)
+}
+
+func TestLoadNameAliases(t *testing.T) {
+	path := writeFile(t, t.TempDir(), "NameAliases.txt", `# NameAliases-16.0.0.txt
+# Date: 2024-04-24
+0009;CHARACTER TABULATION;control
+0009;HORIZONTAL TABULATION;control
+0009;HT;abbreviation
+`)
+	headers := DataHeaders{}
+	names := NewCharNames()
+	err := LoadNameAliases(path, names, &headers)
+	assert.Nil(t, err)
+	assert.Equal(t, []DataHeader{{Filename: "NameAliases-16.0.0.txt", Created: "Date: 2024-04-24"}}, headers.List)
+	assert.Equal(t, []string{"CHARACTER TABULATION", "HORIZONTAL TABULATION", "HT"}, names.Aliases(0x09))
+	assert.Nil(t, names.Aliases(0x0A))
+
+	path = writeFile(t, t.TempDir(), "NameAliases.txt", "0009;HT\n")
+	err = LoadNameAliases(path, names, &headers)
+	assert.NotNil(t, err)
+}
+
+func TestAlgorithmicName(t *testing.T) {
+	names := NewCharNames()
+	names.AddRange(set.RuneRange{First: 0xAC00, Last: 0xD7A3}, "Hangul Syllable")
+	names.AddRange(set.RuneRange{First: 0x4E00, Last: 0x9FFF}, "CJK Ideograph")
+	names.AddRange(set.RuneRange{First: 0x17000, Last: 0x187F7}, "Tangut Ideograph")
+	names.AddRange(set.RuneRange{First: 0xE000, Last: 0xF8FF}, "Private Use")
+
+	testCases := []struct {
+		r    rune
+		name string
+	}{
+		{0xAC00, "HANGUL SYLLABLE GA"},
+		{0xAC01, "HANGUL SYLLABLE GAG"},
+		{0xD4DB, "HANGUL SYLLABLE PWILH"},
+		{0xD7A3, "HANGUL SYLLABLE HIH"},
+		{0xC544, "HANGUL SYLLABLE A"},
+		{0x4E00, "CJK UNIFIED IDEOGRAPH-4E00"},
+		{0x17000, "TANGUT IDEOGRAPH-17000"},
+		{0xE000, "<Private Use>"},
+	}
+	for _, testCase := range testCases {
+		name, ok := names.Lookup(testCase.r)
+		assert.True(t, ok)
+		assert.Equal(t, testCase.name, name)
+	}
+
+	count := 0
+	for r, name := range names.Each {
+		assert.NotEqual(t, "<Private Use>", name)
+		assert.False(t, 0xE000 <= r && r <= 0xF8FF)
+		count++
+	}
+	assert.Equal(t, 0xD7A3-0xAC00+1+0x9FFF-0x4E00+1+0x187F7-0x17000+1, count)
+}
+
+func TestLoadAgeMap(t *testing.T) {
+	path := writeFile(t, t.TempDir(), "DerivedAge.txt", `# DerivedAge-16.0.0.txt
+# Date: 2024-04-30
+0000..001F    ; 1.1 #  [32] <control-0000>..<control-001F>
+0041          ; 1.1 #       LATIN CAPITAL LETTER A
+20C1          ; 16.0 #      SAUDI RIYAL SIGN
+0860..086A    ; 10.0 #  [11] SYRIAC LETTER MALAYALAM NGA..SYRIAC LETTER MALAYALAM SSA
+20BF          ; 10.0 #       BITCOIN SIGN
+0221          ; 4.0 #       LATIN SMALL LETTER D WITH CURL
+`)
+	headers := DataHeaders{}
+	def, setMap, err := LoadAgeMap(path, &headers)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"1.1", "4.0", "10.0", "16.0", "NA"}, def.propertyToName)
+	assert.Equal(t, "{0x0000..0x001f,0x0041..0x0041}", setMap[0].String())
+	assert.Equal(t, "{0x0860..0x086a,0x20bf..0x20bf}", setMap[2].String())
+	assert.Equal(t, "{0x0020..0x0040,0x0042..0x0220,0x0222..0x085f,0x086b..0x20be,0x20c0..0x20c0,0x20c2..0x10ffff}", setMap[4].String())
+
+	ages, err := AgesUpTo(def, "10.0", NewAliasMap())
+	assert.Nil(t, err)
+	assert.Equal(t, []Age{0, 1, 2}, ages)
+	ages, err = AgesUpTo(def, "1.0", NewAliasMap())
+	assert.Nil(t, err)
+	assert.Empty(t, ages)
+
+	path = writeFile(t, t.TempDir(), "DerivedAge.txt", "0000..001F    ; V1_1\n")
+	_, _, err = LoadAgeMap(path, &headers)
+	assert.NotNil(t, err)
+}
+
+func TestLoadBlockMap(t *testing.T) {
+	path := writeFile(t, t.TempDir(), "Blocks.txt", `# Blocks-16.0.0.txt
+# Date: 2024-02-02
+0000..007F; Basic Latin
+0080..00FF; Latin-1 Supplement
+2500..257F; Box Drawing
+`)
+	aliasMap := NewAliasMap()
+	aliasMap.AddAll("ASCII", []string{"Basic_Latin"})
+	aliasMap.AddAll("Latin_1_Sup", []string{"Latin_1_Supplement", "Latin_1"})
+	aliasMap.AddAll("NB", []string{"No_Block"})
+	headers := DataHeaders{}
+	def, setMap, err := LoadBlockMap(path, aliasMap, &headers)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"ASCII", "Latin_1_Sup", "Box_Drawing", "NB"}, def.propertyToName)
+	assert.Equal(t, "{0x0080..0x00ff}", setMap[1].String())
+	assert.Equal(t, "{0x2500..0x257f}", setMap[2].String())
+	assert.Equal(t, "{0x0100..0x24ff,0x2580..0x10ffff}", setMap[3].String())
+	assert.Equal(t, []string{"Latin_1_Sup", "Latin_1_Supplement", "Latin_1"}, def.Names(1, aliasMap))
+}
+
+func TestLoadLineBreakMap(t *testing.T) {
+	path := writeFile(t, t.TempDir(), "LineBreak.txt", `# LineBreak-16.0.0.txt
+# Date: 2024-02-02
+0020;SP           # Zs         SPACE
+002D;HY           # Pd         HYPHEN-MINUS
+E000..F8FF;XX     # Co  [6400] <private-use-E000>..<private-use-F8FF>
+`)
+	headers := DataHeaders{}
+	def, setMap, err := LoadLineBreakMap(path, &headers)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"SP", "HY", "XX"}, def.propertyToName)
+	assert.Equal(t, "{0x0000..0x001f,0x0021..0x002c,0x002e..0x10ffff}", setMap[2].String())
+
+	path = writeFile(t, t.TempDir(), "LineBreak.txt", "0020;SP\n")
+	def, setMap, err = LoadLineBreakMap(path, &headers)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"SP", "XX"}, def.propertyToName)
+	assert.Equal(t, "{0x0000..0x001f,0x0021..0x10ffff}", setMap[1].String())
+
+	path = writeFile(t, t.TempDir(), "LineBreak.txt", `# LineBreak-16.0.0.txt
+# Date: 2024-02-02
+# @missing: 0000..10FFFF; XX
+# @missing: 3400..4DBF; ID
+# @missing: 20A0..20CF; PR
+0020;SP           # Zs         SPACE
+20AC;PR           # Sc         EURO SIGN
+3400..4DBF;ID     # Lo  [6592] CJK UNIFIED IDEOGRAPH-3400..CJK UNIFIED IDEOGRAPH-4DBF
+`)
+	def, setMap, err = LoadLineBreakMap(path, &headers)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"SP", "PR", "ID", "XX"}, def.propertyToName)
+	assert.Equal(t, "{0x20a0..0x20cf}", setMap[1].String())
+	assert.Equal(t, "{0x3400..0x4dbf}", setMap[2].String())
+	assert.Equal(t, "{0x0000..0x001f,0x0021..0x209f,0x20d0..0x33ff,0x4dc0..0x10ffff}", setMap[3].String())
+}
+
+func TestLoadMissing(t *testing.T) {
+	path := writeFile(t, t.TempDir(), "GraphemeBreakProperty.txt", `# GraphemeBreakProperty-16.0.0.txt
+# Date: 2024-05-31
+# @missing: 0000..10FFFF; Other
+000D          ; CR # Cc       <control-000D>
+000A          ; LF # Cc       <control-000A>
+`)
+	headers := DataHeaders{}
+	def, setMap, err := LoadPropertyMap[GraphemeBreakProperty](path, &headers)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"CR", "LF", "Other"}, def.propertyToName)
+	assert.Equal(t, "{0x0000..0x0009,0x000b..0x000c,0x000e..0x10ffff}", setMap[2].String())
+
+	// multiple property values or placeholders are not applied
+	path = writeFile(t, t.TempDir(), "DerivedNormalizationProps.txt", `# DerivedNormalizationProps-16.0.0.txt
+# Date: 2024-04-30
+# @missing: 0000..10FFFF; FC_NFKC; <code point>
+# @missing: 0000..10FFFF; NFD_QC; Yes
+0340..0341    ; NFD_QC; N # Mn   [2] COMBINING GRAVE TONE MARK..COMBINING ACUTE TONE MARK
+`)
+	normDef, _, err := LoadPropertyMap[DerivedNormalizationProp](path, &headers)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"NFD_QC"}, normDef.propertyToName)
+
+	// long names are replaced with abbreviations
+	path = writeFile(t, t.TempDir(), "DerivedJoiningType.txt", `# DerivedJoiningType-16.0.0.txt
+# Date: 2024-04-30
+# @missing: 0000..10FFFF; Non_Joining
+0640          ; C # Lm       ARABIC TATWEEL
+`)
+	aliasMap := NewAliasMap()
+	aliasMap.AddAll("U", []string{"Non_Joining"})
+	jtDef, jtMap, err := LoadPropertyMapWithDefault[JoiningType](path, JoiningTypeDefault, aliasMap, &headers)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"C", "U"}, jtDef.propertyToName)
+	assert.Equal(t, "{0x0000..0x063f,0x0641..0x10ffff}", jtMap[1].String())
+
+	path = writeFile(t, t.TempDir(), "EastAsianWidth.txt", `# EastAsianWidth-16.0.0.txt
+# Date: 2024-04-30
+# @missing: 0000..10FFFF; N
+# @missing: 3400..4DBF; W
+0020;Na          # Zs         SPACE
+3400..3401;W     # Lo     [2] CJK UNIFIED IDEOGRAPH-3400..CJK UNIFIED IDEOGRAPH-3401
+`)
+	eawMap, err := LoadEastAsianWidthMap(path, &headers)
+	assert.Nil(t, err)
+	assert.Equal(t, "{0x3400..0x4dbf}", eawMap[EAW_W].String())
+	assert.Equal(t, "{0x0000..0x001f,0x0021..0x33ff,0x4dc0..0x10ffff}", eawMap[EAW_N].String())
+	assert.Equal(t, "{}", eawMap[EAW_A].String())
+	assert.Equal(t, "{0x0000..0x001f,0x0021..0x33ff,0x3402..0x10ffff}", headers.Unlisted[path].String())
+
+	path = writeFile(t, t.TempDir(), "Scripts.txt", `# Scripts-16.0.0.txt
+# Date: 2024-04-30
+# @missing: 0000..10FFFF; Unknown
+0041..005A    ; Latin # L&  [26] LATIN CAPITAL LETTER A..LATIN CAPITAL LETTER Z
+`)
+	scriptAliasMap := NewAliasMap()
+	scriptAliasMap.AddAll("Latn", []string{"Latin"})
+	scriptAliasMap.AddAll("Zzzz", []string{"Unknown"})
+	scriptDef, scriptMap, err := LoadScriptMap(path, scriptAliasMap, &headers)
+	assert.Nil(t, err)
+	assert.Equal(t, Script(1), scriptDef.Unknown())
+	assert.Equal(t, []string{"Latn", "Zzzz"}, scriptDef.scriptToAbbr)
+	assert.Equal(t, "{0x0000..0x0040,0x005b..0x10ffff}", scriptMap[scriptDef.Unknown()].String())
+}
+
+func TestLoadPropertyMapWithDefault(t *testing.T) {
+	path := writeFile(t, t.TempDir(), "HangulSyllableType.txt", `# HangulSyllableType-16.0.0.txt
+# Date: 2024-02-02
+1100..115F    ; L # Lo  [96] HANGUL CHOSEONG KIYEOK..HANGUL CHOSEONG FILLER
+AC00          ; LV # Lo       HANGUL SYLLABLE GA
+AC01..AC1B    ; LVT # Lo  [27] HANGUL SYLLABLE GAG..HANGUL SYLLABLE GAH
+`)
+	headers := DataHeaders{}
+	def, setMap, err := LoadPropertyMapWithDefault[HangulSyllableType](path, HangulSyllableTypeDefault, nil, &headers)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"L", "LV", "LVT", "NA"}, def.propertyToName)
+	assert.Equal(t, "{0x0000..0x10ff,0x1160..0xabff,0xac1c..0x10ffff}", setMap[3].String())
+
+	path = writeFile(t, t.TempDir(), "VerticalOrientation.txt", `# VerticalOrientation-16.0.0.txt
+# Date: 2024-02-02
+0000..00A6    ; R
+00A7          ; U
+3001..3002    ; Tu
+`)
+	voDef, voMap, err := LoadPropertyMapWithDefault[VerticalOrientation](path, VerticalOrientationDefault, nil, &headers)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"R", "U", "Tu"}, voDef.propertyToName)
+	assert.Equal(t, "{0x0000..0x00a6,0x00a8..0x3000,0x3003..0x10ffff}", voMap[0].String())
+}
+
+func TestLoadBidiMirroring(t *testing.T) {
+	path := writeFile(t, t.TempDir(), "BidiMirroring.txt", `# BidiMirroring-16.0.0.txt
+# Date: 2024-01-30
+0028; 0029 # LEFT PARENTHESIS
+0029; 0028 # RIGHT PARENTHESIS
+`)
+	headers := DataHeaders{}
+	mirroringMap, err := LoadBidiMirroringMap(path, &headers)
+	assert.Nil(t, err)
+	assert.Equal(t, RuneMap{0x28: 0x29, 0x29: 0x28}, mirroringMap)
+
+	path = writeFile(t, t.TempDir(), "BidiBrackets.txt", `# BidiBrackets-16.0.0.txt
+# Date: 2024-02-02
+0028; 0029; o # LEFT PARENTHESIS
+0029; 0028; c # RIGHT PARENTHESIS
+005B; 005D; o # LEFT SQUARE BRACKET
+`)
+	def, setMap, err := LoadBidiBracketsMap(path, &headers)
+	assert.Nil(t, err)
+	assert.Equal(t, "{0x0028..0x0028,0x005b..0x005b}", setMap[0].String())
+	assert.Equal(t, "{0x0029..0x0029}", setMap[1].String())
+	none, err := def.Parse(BidiPairedBracketTypeNone)
+	assert.Nil(t, err)
+	assert.Equal(t, "{0x0000..0x0027,0x002a..0x005a,0x005c..0x10ffff}", setMap[none].String())
+
+	path = writeFile(t, t.TempDir(), "BidiBrackets.txt", "0028; 0029; x\n")
+	_, _, err = LoadBidiBracketsMap(path, &headers)
+	assert.NotNil(t, err)
+}
+
+func TestLoadSpecialCasing(t *testing.T) {
+	path := writeFile(t, t.TempDir(), "UnicodeData.txt", testUnicodeData+
+		"00DF;LATIN SMALL LETTER SHARP S;Ll;0;L;;;;;N;;;;;\n"+
+		"01C4;LATIN CAPITAL LETTER DZ WITH CARON;Lu;0;L;<compat> 0044 017D;;;;N;;;;01C6;01C5\n")
+	headers := DataHeaders{}
+	record, err := LoadUnicodeData(path, &headers)
+	assert.Nil(t, err)
+	caseMapping := record.CaseMapping
+	assert.Equal(t, []rune{0x61}, caseMapping.LookupLower(0x41))
+	assert.Equal(t, []rune{0x41}, caseMapping.LookupUpper(0x41))
+	assert.Equal(t, []rune{0x1C5}, caseMapping.LookupTitle(0x1C4))
+	assert.Equal(t, []rune{0xDF}, caseMapping.LookupUpper(0xDF))
+	assert.Equal(t, RuneStringMap{0x41: {0x61}, 0xC0: {0xE0}, 0x1C4: {0x1C6}}, caseMapping.LowerMap())
+
+	path = writeFile(t, t.TempDir(), "SpecialCasing.txt", `# SpecialCasing-16.0.0.txt
+# Date: 2024-05-10
+00DF; 00DF; 0053 0073; 0053 0053; # LATIN SMALL LETTER SHARP S
+03A3; 03C2; 03A3; 03A3; Final_Sigma; # GREEK CAPITAL LETTER SIGMA
+0049; 0131; 0049; 0049; tr; # LATIN CAPITAL LETTER I
+`)
+	err = LoadSpecialCasing(path, caseMapping, &headers)
+	assert.Nil(t, err)
+	assert.Equal(t, []rune{0x53, 0x53}, caseMapping.LookupUpper(0xDF))
+	assert.Equal(t, []rune{0x53, 0x73}, caseMapping.LookupTitle(0xDF))
+	assert.Equal(t, []rune{0xDF}, caseMapping.LookupLower(0xDF))
+	assert.Equal(t, []rune{0x3A3}, caseMapping.LookupLower(0x3A3)) // conditional mapping is ignored
+	assert.Equal(t, []rune{0x49}, caseMapping.LookupLower(0x49))
+
+	path = writeFile(t, t.TempDir(), "SpecialCasing.txt", "00DF; 00DF; 0053 0073\n")
+	err = LoadSpecialCasing(path, caseMapping, &headers)
+	assert.NotNil(t, err)
+}
+
+func TestLoadFullCaseFolding(t *testing.T) {
+	path := writeFile(t, t.TempDir(), "CaseFolding.txt", `# CaseFolding-16.0.0.txt
+# Date: 2024-04-30
+0041; C; 0061; # LATIN CAPITAL LETTER A
+00DF; F; 0073 0073; # LATIN SMALL LETTER SHARP S
+1E9E; F; 0073 0073; # LATIN CAPITAL LETTER SHARP S
+1E9E; S; 00DF; # LATIN CAPITAL LETTER SHARP S
+0049; T; 0131; # LATIN CAPITAL LETTER I
+`)
+	headers := DataHeaders{}
+	foldMap, err := LoadCaseFoldingMap(path, &headers)
+	assert.Nil(t, err)
+	assert.Equal(t, rune(0xDF), foldMap.LookupFold(0x1E9E))
+	assert.Equal(t, rune(0xDF), foldMap.LookupFold(0xDF))
+	assert.Equal(t, []rune{0x73, 0x73}, foldMap.LookupFullFold(0x1E9E))
+	assert.Equal(t, []rune{0x73, 0x73}, foldMap.LookupFullFold(0xDF))
+	assert.Equal(t, []rune{0x61}, foldMap.LookupFullFold(0x41))
+	assert.Equal(t, []rune{0x49}, foldMap.LookupFullFold(0x49))
+	assert.Equal(t, RuneStringMap{0x41: {0x61}, 0xDF: {0x73, 0x73}, 0x1E9E: {0x73, 0x73}}, foldMap.FullFoldMap())
+}
+
+func TestCaseClosure(t *testing.T) {
+	path := writeFile(t, t.TempDir(), "CaseFolding.txt", `# CaseFolding-16.0.0.txt
+# Date: 2024-04-30
+0049; C; 0069; # LATIN CAPITAL LETTER I
+0049; T; 0131; # LATIN CAPITAL LETTER I
+004B; C; 006B; # LATIN CAPITAL LETTER K
+0053; C; 0073; # LATIN CAPITAL LETTER S
+0130; T; 0069; # LATIN CAPITAL LETTER I WITH DOT ABOVE
+017F; C; 0073; # LATIN SMALL LETTER LONG S
+212A; C; 006B; # KELVIN SIGN
+`)
+	headers := DataHeaders{}
+	foldMap, err := LoadCaseFoldingMap(path, &headers)
+	assert.Nil(t, err)
+	assert.ElementsMatch(t, []rune{0x4B, 0x6B, 0x212A}, foldMap.Closure(0x212A, false))
+	assert.ElementsMatch(t, []rune{0x53, 0x73, 0x17F}, foldMap.Closure(0x53, false))
+	assert.ElementsMatch(t, []rune{0x49, 0x69}, foldMap.Closure(0x69, false))
+	assert.ElementsMatch(t, []rune{0x69, 0x130}, foldMap.Closure(0x69, true))
+	assert.ElementsMatch(t, []rune{0x49, 0x131}, foldMap.Closure(0x49, true))
+	assert.ElementsMatch(t, []rune{0x49, 0x131}, foldMap.Closure(0x131, true))
+	assert.ElementsMatch(t, []rune{0x69, 0x130}, foldMap.Closure(0x130, true))
+	assert.ElementsMatch(t, []rune{0x4B, 0x6B, 0x212A}, foldMap.Closure(0x6B, true))
+	assert.ElementsMatch(t, []rune{0x130}, foldMap.Closure(0x130, false))
+	assert.ElementsMatch(t, []rune{0x30}, foldMap.Closure(0x30, true))
+}
+
+func TestLoadIdentifierTypeMap(t *testing.T) {
+	path := writeFile(t, t.TempDir(), "IdentifierType.txt", `# IdentifierType.txt
+# Date: 2024-07-31
+0027          ; Inclusion                      # 1.1        APOSTROPHE
+0030..0039    ; Recommended                    # 1.1    [10] DIGIT ZERO..DIGIT NINE
+00B7          ; Exclusion Not_XID              # 1.1        MIDDLE DOT
+`)
+	headers := DataHeaders{}
+	def, setMap, err := LoadIdentifierTypeMap(path, &headers)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Inclusion", "Recommended", "Exclusion", "Not_XID", "Not_Character"}, def.propertyToName)
+	exclusion, _ := def.Parse("Exclusion")
+	notXID, _ := def.Parse("Not_XID")
+	notCharacter, _ := def.Parse("Not_Character")
+	assert.Equal(t, "{0x00b7..0x00b7}", setMap[exclusion].String())
+	assert.Equal(t, "{0x00b7..0x00b7}", setMap[notXID].String())
+	assert.Equal(t, "{0x0000..0x0026,0x0028..0x002f,0x003a..0x00b6,0x00b8..0x10ffff}", setMap[notCharacter].String())
+}
+
+func TestConfusables(t *testing.T) {
+	path := writeFile(t, t.TempDir(), "UnicodeData.txt", testUnicodeData+
+		"006D;LATIN SMALL LETTER M;Ll;0;L;;;;;N;;;004D;;004D\n"+
+		"0323;COMBINING DOT BELOW;Mn;220;NSM;;;;;N;NON-SPACING DOT BELOW;;;;\n"+
+		"1E0C;LATIN CAPITAL LETTER D WITH DOT BELOW;Lu;0;L;0044 0323;;;;N;;;;1E0D;\n")
+	headers := DataHeaders{}
+	record, err := LoadUnicodeData(path, &headers)
+	assert.Nil(t, err)
+	normalizer := record.Normalizer
+	assert.Equal(t, []rune{0x41, 0x300}, normalizer.NFD([]rune{0xC0}))
+	assert.Equal(t, []rune{0x44, 0x323, 0x300}, normalizer.NFD([]rune{0x1E0C, 0x300}))
+	assert.Equal(t, []rune{0x44, 0x323, 0x300}, normalizer.NFD([]rune{0x44, 0x300, 0x323})) // canonical ordering
+	assert.Equal(t, []rune{0x1100, 0x1161, 0x11A8}, normalizer.NFD([]rune{0xAC01}))         // Hangul syllable
+
+	path = writeFile(t, t.TempDir(), "confusables.txt", "\uFEFF# confusables.txt\n# Date: 2024-08-14\n"+
+		"006D ;\t0072 006E ;\tMA\t# ( m → rn ) LATIN SMALL LETTER M → LATIN SMALL LETTER R, LATIN SMALL LETTER N\t#\n"+
+		"0031 ;\t006C ;\tMA\t# ( 1 → l ) DIGIT ONE → LATIN SMALL LETTER L\t#\n"+
+		"0049 ;\t006C ;\tMA\t# ( I → l ) LATIN CAPITAL LETTER I → LATIN SMALL LETTER L\t#\n")
+	confusables, err := LoadConfusables(path, &headers)
+	assert.Nil(t, err)
+	assert.Equal(t, DataHeader{Filename: "confusables.txt", Created: "Date: 2024-08-14"}, headers.List[len(headers.List)-1])
+	ignorables := &set.UniSet{}
+	ignorables.Add(0x200B)
+	assert.Equal(t, []rune{0x72, 0x6E}, confusables.Skeleton([]rune{0x6D}, normalizer, ignorables))
+	assert.Equal(t, []rune{0x72, 0x6E}, confusables.Skeleton([]rune{0x72, 0x6E}, normalizer, ignorables))
+	assert.Equal(t, []rune{0x6C, 0x41, 0x300}, confusables.Skeleton([]rune{0x49, 0xC0}, normalizer, ignorables))
+	assert.Equal(t, confusables.Skeleton([]rune("paypal"), normalizer, ignorables),
+		confusables.Skeleton([]rune("pa\u200Bypal"), normalizer, ignorables)) // Default_Ignorable_Code_Point is removed
+	assert.Equal(t, []rune{0x6D}, confusables.Sources([]rune{0x72, 0x6E}, normalizer, ignorables))
+	assert.Equal(t, []rune{0x31, 0x49}, confusables.Sources([]rune{0x6C}, normalizer, ignorables))
+	assert.Empty(t, confusables.Sources([]rune{0x41}, normalizer, ignorables))
+
+	path = writeFile(t, t.TempDir(), "confusables.txt", "006D ;\t0072 006E\n")
+	_, err = LoadConfusables(path, &headers)
+	assert.NotNil(t, err)
+}
+
+func TestLoadNumericMap(t *testing.T) {
+	aliasMap := NewAliasMap()
+	aliasMap.AddAll("De", []string{"Decimal"})
+	aliasMap.AddAll("Nu", []string{"Numeric"})
+	path := writeFile(t, t.TempDir(), "DerivedNumericType.txt", `# DerivedNumericType-16.0.0.txt
+# Date: 2024-05-31
+0030..0039    ; Decimal # Nd  [10] DIGIT ZERO..DIGIT NINE
+00BD          ; Numeric # No       VULGAR FRACTION ONE HALF
+0F33          ; Numeric # No       TIBETAN DIGIT HALF ZERO
+`)
+	headers := DataHeaders{}
+	def, setMap, err := LoadNumericTypeMap(path, aliasMap, &headers)
+	assert.Nil(t, err)
+	assert.Equal(t, "{0x0030..0x0039}", setMap[0].String())
+	assert.Equal(t, "{0x00bd..0x00bd,0x0f33..0x0f33}", setMap[2].String())
+	none, _ := def.Parse(NumericTypeNone)
+	assert.Equal(t, "{0x0000..0x002f,0x003a..0x00bc,0x00be..0x0f32,0x0f34..0x10ffff}", setMap[none].String())
+	_, ok := setMap[1]
+	assert.False(t, ok)
+
+	path = writeFile(t, t.TempDir(), "DerivedNumericValues.txt", `# DerivedNumericValues-16.0.0.txt
+# Date: 2024-05-31
+0035          ; 5.0 ; ; 5 # Nd       DIGIT FIVE
+00BD          ; 0.5 ; ; 1/2 # No       VULGAR FRACTION ONE HALF
+0F33          ; -0.5 ; ; -1/2 # No       TIBETAN DIGIT HALF ZERO
+0030          ; 0.0 ; ; 0 # Nd       DIGIT ZERO
+0F2A          ; 0.5 ; ; 1/2 # No       TIBETAN DIGIT HALF ONE
+`)
+	nvDef, nvMap, err := LoadNumericValueMap(path, &headers)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"-1/2", "0", "1/2", "5", "NaN"}, nvDef.propertyToName)
+	half, err := nvDef.Parse("1/2")
+	assert.Nil(t, err)
+	assert.Equal(t, "{0x00bd..0x00bd,0x0f2a..0x0f2a}", nvMap[half].String())
+	assert.Equal(t, "{0x0000..0x002f,0x0031..0x0034,0x0036..0x00bc,0x00be..0x0f29,0x0f2b..0x0f32,0x0f34..0x10ffff}",
+		nvMap[4].String())
+
+	path = writeFile(t, t.TempDir(), "DerivedNumericValues.txt", "0035 ; 5.0 ; ; 5/0\n")
+	_, _, err = LoadNumericValueMap(path, &headers)
+	assert.NotNil(t, err)
+}
+
+func TestLoadBidiClassMap(t *testing.T) {
+	aliasMap := NewAliasMap()
+	aliasMap.AddAll("L", []string{"Left_To_Right"})
+	aliasMap.AddAll("R", []string{"Right_To_Left"})
+	aliasMap.AddAll("AL", []string{"Arabic_Letter"})
+	aliasMap.AddAll("BN", []string{"Boundary_Neutral"})
+	path := writeFile(t, t.TempDir(), "DerivedBidiClass.txt", `# DerivedBidiClass-16.0.0.txt
+# Date: 2024-02-02
+# @missing: 0000..10FFFF; Left_To_Right
+# @missing: 0590..05FF; Right_To_Left
+# @missing: 0600..07BF; Arabic_Letter
+# @missing: FDD0..FDEF; Boundary_Neutral
+
+0041..005A    ; L  # L&  [26] LATIN CAPITAL LETTER A..LATIN CAPITAL LETTER Z
+05D0..05EA    ; R  # Lo  [27] HEBREW LETTER ALEF..HEBREW LETTER TAV
+0627          ; AL # Lo       ARABIC LETTER ALEF
+0660..0669    ; AN # Nd  [10] ARABIC-INDIC DIGIT ZERO..ARABIC-INDIC DIGIT NINE
+`)
+	def := NewPropertyDef[BidiClass](bidiClassNames)
+	headers := DataHeaders{}
+	setMap, err := LoadBidiClassMap(path, def, aliasMap, &headers)
+	assert.Nil(t, err)
+	assert.Equal(t, []DataHeader{{Filename: "DerivedBidiClass-16.0.0.txt", Created: "Date: 2024-02-02"}}, headers.List)
+	bc, _ := def.Parse("R")
+	assert.Equal(t, "{0x0590..0x05ff}", setMap[bc].String()) // Hebrew block
+	bc, _ = def.Parse("AL")
+	assert.Equal(t, "{0x0600..0x065f,0x066a..0x07bf}", setMap[bc].String()) // Arabic blocks except AN
+	bc, _ = def.Parse("AN")
+	assert.Equal(t, "{0x0660..0x0669}", setMap[bc].String())
+	bc, _ = def.Parse("BN")
+	assert.Equal(t, "{0xfdd0..0xfdef}", setMap[bc].String())
+	bc, _ = def.Parse("L")
+	assert.Equal(t, "{0x0000..0x058f,0x07c0..0xfdcf,0xfdf0..0x10ffff}", setMap[bc].String())
+
+	path = writeFile(t, t.TempDir(), "DerivedBidiClass.txt", "0041 ; XX\n")
+	_, err = LoadBidiClassMap(path, def, aliasMap, &headers)
+	assert.NotNil(t, err)
+}
+
+func TestLoadJoiningGroupMap(t *testing.T) {
+	aliasMap := NewAliasMap()
+	aliasMap.AddAll("African_Feh", []string{"African_Feh"})
+	aliasMap.AddAll("No_Joining_Group", []string{"No_Joining_Group"})
+	path := writeFile(t, t.TempDir(), "ArabicShaping.txt", `# ArabicShaping-16.0.0.txt
+# Date: 2024-02-13
+0600; ARABIC NUMBER SIGN; U; No_Joining_Group
+0628; BEH; D; BEH
+062A; TEH; D; BEH
+08BB; AFRICAN FEH; D; AFRICAN FEH
+`)
+	headers := DataHeaders{}
+	def, setMap, err := LoadJoiningGroupMap(path, aliasMap, &headers)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"No_Joining_Group", "BEH", "African_Feh"}, def.propertyToName)
+	assert.Equal(t, "{0x0628..0x0628,0x062a..0x062a}", setMap[1].String())
+	assert.Equal(t, "{0x08bb..0x08bb}", setMap[2].String())
+	assert.Equal(t, "{0x0000..0x0627,0x0629..0x0629,0x062b..0x08ba,0x08bc..0x10ffff}", setMap[0].String())
+
+	path = writeFile(t, t.TempDir(), "ArabicShaping.txt", "0628; BEH; D\n")
+	_, _, err = LoadJoiningGroupMap(path, aliasMap, &headers)
+	assert.NotNil(t, err)
+}
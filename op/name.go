@@ -0,0 +1,164 @@
+package op
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/sekiguchi-nagisa/guniset/set"
+)
+
+// NamedRange code point range defined by `<..., First>` and `<..., Last>` entries of UnicodeData.txt
+type NamedRange struct {
+	set.RuneRange
+	Label string // such as `CJK Ideograph Extension A`
+}
+
+// CharNames character names defined in UnicodeData.txt and NameAliases.txt
+type CharNames struct {
+	names   map[rune]string
+	labels  map[rune]string // pseudo names such as `<control>` (not character names)
+	aliases map[rune][]string
+	ranges  []NamedRange
+}
+
+func NewCharNames() *CharNames {
+	return &CharNames{names: make(map[rune]string), labels: make(map[rune]string), aliases: make(map[rune][]string)}
+}
+
+// Add add character name. pseudo name such as `<control>` is only used for Lookup
+func (c *CharNames) Add(r rune, name string) {
+	if strings.HasPrefix(name, "<") {
+		c.labels[r] = name
+		return
+	}
+	c.names[r] = name
+}
+
+func (c *CharNames) AddAlias(r rune, alias string) {
+	c.aliases[r] = append(c.aliases[r], alias)
+}
+
+func (c *CharNames) AddRange(runeRange set.RuneRange, label string) {
+	c.ranges = append(c.ranges, NamedRange{RuneRange: runeRange, Label: label})
+}
+
+// Lookup returns character name. if r is in named range, returns algorithmic name (NR1, NR2) or `<label>`
+func (c *CharNames) Lookup(r rune) (string, bool) {
+	if name, ok := c.names[r]; ok {
+		return name, true
+	}
+	if label, ok := c.labels[r]; ok {
+		return label, true
+	}
+	for _, namedRange := range c.ranges {
+		if namedRange.First <= r && r <= namedRange.Last {
+			if name, ok := algorithmicName(namedRange.Label, r); ok {
+				return name, true
+			}
+			return "<" + namedRange.Label + ">", true
+		}
+	}
+	return "", false
+}
+
+// Aliases returns name aliases defined in NameAliases.txt
+func (c *CharNames) Aliases(r rune) []string {
+	return c.aliases[r]
+}
+
+// Each iterates character names, name aliases and algorithmic names.
+// pseudo names (such as `<control>`) and code points in named ranges without algorithmic name
+// (such as private use) are skipped
+func (c *CharNames) Each(yield func(rune, string) bool) {
+	for r, name := range c.names {
+		if !yield(r, name) {
+			return
+		}
+	}
+	for r, aliases := range c.aliases {
+		for _, alias := range aliases {
+			if !yield(r, alias) {
+				return
+			}
+		}
+	}
+	for _, namedRange := range c.ranges {
+		for r := namedRange.First; r <= namedRange.Last; r++ {
+			name, ok := algorithmicName(namedRange.Label, r)
+			if !ok {
+				break
+			}
+			if !yield(r, name) {
+				return
+			}
+		}
+	}
+}
+
+// for Hangul syllable name generation (NR1)
+const (
+	hangulSBase  = 0xAC00
+	hangulLCount = 19
+	hangulVCount = 21
+	hangulTCount = 28
+	hangulNCount = hangulVCount * hangulTCount
+	hangulSCount = hangulLCount * hangulNCount
+)
+
+var hangulJamoL = [hangulLCount]string{
+	"G", "GG", "N", "D", "DD", "R", "M", "B", "BB", "S", "SS", "", "J", "JJ", "C", "K", "T", "P", "H",
+}
+
+var hangulJamoV = [hangulVCount]string{
+	"A", "AE", "YA", "YAE", "EO", "E", "YEO", "YE", "O", "WA", "WAE", "OE", "YO", "U", "WEO", "WE", "WI",
+	"YU", "EU", "YI", "I",
+}
+
+var hangulJamoT = [hangulTCount]string{
+	"", "G", "GG", "GS", "N", "NJ", "NH", "D", "L", "LG", "LM", "LB", "LS", "LT", "LP", "LH", "M", "B",
+	"BS", "S", "SS", "NG", "J", "C", "K", "T", "P", "H",
+}
+
+func hangulSyllableName(r rune) (string, bool) {
+	s := int(r) - hangulSBase
+	if s < 0 || s >= hangulSCount {
+		return "", false
+	}
+	l := s / hangulNCount
+	v := (s % hangulNCount) / hangulTCount
+	t := s % hangulTCount
+	return "HANGUL SYLLABLE " + hangulJamoL[l] + hangulJamoV[v] + hangulJamoT[t], true
+}
+
+// algorithmicName derive character name from label of named range
+func algorithmicName(label string, r rune) (string, bool) {
+	switch {
+	case label == "Hangul Syllable":
+		return hangulSyllableName(r)
+	case strings.HasPrefix(label, "CJK Ideograph"):
+		return fmt.Sprintf("CJK UNIFIED IDEOGRAPH-%04X", r), true
+	case strings.HasPrefix(label, "Tangut Ideograph"):
+		return fmt.Sprintf("TANGUT IDEOGRAPH-%04X", r), true
+	default:
+		return "", false
+	}
+}
+
+// WildcardToRegexp convert wildcard pattern (`*`, `?`) to case-insensitive regexp matching whole name
+func WildcardToRegexp(pattern string) (*regexp.Regexp, error) {
+	sb := strings.Builder{}
+	sb.WriteString("(?i)^")
+	for _, r := range pattern {
+		switch r {
+		case '*':
+			sb.WriteString(".*")
+		case '?':
+			sb.WriteString(".")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	sb.WriteString("$")
+	return regexp.Compile(sb.String())
+}
@@ -1,6 +1,7 @@
 package op
 
 import (
+	"regexp"
 	"slices"
 
 	"github.com/sekiguchi-nagisa/guniset/set"
@@ -65,8 +66,6 @@ func (e *EastAsianWidthNode) Eval(context *EvalContext) set.UniSet {
 	for _, property := range e.properties {
 		if s, ok := context.EawMap[property]; ok {
 			builder.AddSet(s)
-		} else if property == EAW_N {
-			builder.AddSet(context.FillEawN())
 		}
 	}
 	return builder.Build()
@@ -97,14 +96,10 @@ func (e *ScriptNode) Eval(context *EvalContext) set.UniSet {
 		if e.extension {
 			if s, ok := context.ScriptXMap[property]; ok {
 				builder.AddSet(s)
-			} else if property == context.DefRecord.ScriptDef.Unknown() {
-				builder.AddSet(context.FillScriptUnknown())
 			}
 		} else {
 			if s, ok := context.ScriptMap[property]; ok {
 				builder.AddSet(s)
-			} else if property == context.DefRecord.ScriptDef.Unknown() {
-				builder.AddSet(context.FillScriptUnknown())
 			}
 		}
 	}
@@ -147,9 +142,7 @@ func (c *CompNode) Eval(context *EvalContext) set.UniSet {
 	}
 	uniSet := node.Eval(context)
 	if negate {
-		tmp := set.NewUniSetAll()
-		tmp.RemoveSet(&uniSet)
-		uniSet = tmp
+		uniSet = uniSet.Complement()
 	}
 	return uniSet
 }
@@ -214,3 +207,121 @@ func (c *CaseUnfoldNode) Eval(context *EvalContext) set.UniSet {
 	}
 	return builder.Build()
 }
+
+type CaseClosureNode struct { // @closure(SET), @closure(SET, turkic)
+	node   Node
+	turkic bool
+}
+
+// Eval computes case-insensitive equivalence closure under simple case folding
+func (c *CaseClosureNode) Eval(context *EvalContext) set.UniSet {
+	retSet := c.node.Eval(context)
+	builder := set.UniSetBuilder{}
+	for r := range retSet.Iter {
+		for _, r := range context.CaseFoldingMap.Closure(r, c.turkic) {
+			builder.Add(r)
+		}
+	}
+	return builder.Build()
+}
+
+type CaseMappingKind int
+
+const (
+	CaseMappingUpper CaseMappingKind = iota
+	CaseMappingLower
+	CaseMappingTitle
+	CaseMappingFullFold
+)
+
+type CaseMappingNode struct { // @upper(SET), @lower(SET), @title(SET), @fold(SET, full)
+	node Node
+	kind CaseMappingKind
+}
+
+func (c *CaseMappingNode) lookup(context *EvalContext, r rune) []rune {
+	switch c.kind {
+	case CaseMappingUpper:
+		return context.CaseMapping.LookupUpper(r)
+	case CaseMappingLower:
+		return context.CaseMapping.LookupLower(r)
+	case CaseMappingTitle:
+		return context.CaseMapping.LookupTitle(r)
+	default:
+		return context.CaseFoldingMap.LookupFullFold(r)
+	}
+}
+
+// Eval maps code points through full case mapping. code points of multi-code-point results are all added
+func (c *CaseMappingNode) Eval(context *EvalContext) set.UniSet {
+	retSet := c.node.Eval(context)
+	builder := set.UniSetBuilder{}
+	for r := range retSet.Iter {
+		for _, mapped := range c.lookup(context, r) {
+			builder.Add(mapped)
+		}
+	}
+	return builder.Build()
+}
+
+type MirrorNode struct { // @mirror(SET)
+	node Node
+}
+
+// Eval maps code points through Bidi_Mirroring_Glyph. code points without mirroring glyph are removed
+func (m *MirrorNode) Eval(context *EvalContext) set.UniSet {
+	retSet := m.node.Eval(context)
+	builder := set.UniSetBuilder{}
+	for r := range retSet.Iter {
+		if mirrored, ok := context.BidiMirroringMap[r]; ok {
+			builder.Add(mirrored)
+		}
+	}
+	return builder.Build()
+}
+
+type NameNode struct { // name:/ARROW/, @name("*DASH*")
+	pattern *regexp.Regexp
+}
+
+func (n *NameNode) Eval(context *EvalContext) set.UniSet {
+	builder := set.UniSetBuilder{}
+	for r, name := range context.CharNames.Each {
+		if n.pattern.MatchString(name) {
+			builder.Add(r)
+		}
+	}
+	return builder.Build()
+}
+
+type Binding struct { // let name = SET;
+	name     string
+	node     Node
+	refs     []*VarNode // referenced variables in node
+	fileName string
+	pos      Pos
+}
+
+type VarNode struct { // $name
+	name     string
+	binding  *Binding
+	fileName string
+	pos      Pos
+}
+
+func (v *VarNode) Eval(context *EvalContext) set.UniSet {
+	return context.evalBinding(v.binding)
+}
+
+// evalBinding evaluate binding only once per EvalContext
+func (e *EvalContext) evalBinding(binding *Binding) set.UniSet {
+	if e.bindingCache == nil {
+		e.bindingCache = map[*Binding]*set.UniSet{}
+	}
+	uniSet, ok := e.bindingCache[binding]
+	if !ok {
+		uniSet = new(binding.node.Eval(e))
+		e.bindingCache[binding] = uniSet
+	}
+	return uniSet.Copy()
+}
@@ -0,0 +1,79 @@
+package op
+
+import (
+	"slices"
+
+	"github.com/sekiguchi-nagisa/guniset/set"
+)
+
+// Normalizer canonical decomposition (NFD) derived from UnicodeData.txt
+type Normalizer struct {
+	decompositions   RuneStringMap // canonical decomposition mapping (not applied recursively)
+	combiningClasses map[rune]int  // only non-zero canonical combining classes
+}
+
+func NewNormalizer() *Normalizer {
+	return &Normalizer{decompositions: make(RuneStringMap), combiningClasses: make(map[rune]int)}
+}
+
+func (n *Normalizer) AddDecomposition(r rune, decomposition []rune) {
+	n.decompositions[r] = decomposition
+}
+
+func (n *Normalizer) SetCombiningClass(r rune, ccc int) {
+	if ccc != 0 {
+		n.combiningClasses[r] = ccc
+	}
+}
+
+// for Hangul syllable decomposition
+const (
+	hangulLBase = 0x1100
+	hangulVBase = 0x1161
+	hangulTBase = 0x11A7
+)
+
+func isHangulSyllable(runeRange set.RuneRange) bool {
+	return hangulSBase <= runeRange.First && runeRange.Last < hangulSBase+hangulSCount
+}
+
+func (n *Normalizer) decompose(r rune, buf []rune) []rune {
+	if s := int(r) - hangulSBase; s >= 0 && s < hangulSCount {
+		buf = append(buf, rune(hangulLBase+s/hangulNCount), rune(hangulVBase+(s%hangulNCount)/hangulTCount))
+		if t := s % hangulTCount; t != 0 {
+			buf = append(buf, rune(hangulTBase+t))
+		}
+		return buf
+	}
+	if decomposition, ok := n.decompositions[r]; ok {
+		for _, d := range decomposition {
+			buf = n.decompose(d, buf)
+		}
+		return buf
+	}
+	return append(buf, r)
+}
+
+// NFD returns canonical decomposition with canonical ordering of combining marks
+func (n *Normalizer) NFD(runes []rune) []rune {
+	var ret []rune
+	for _, r := range runes {
+		ret = n.decompose(r, ret)
+	}
+	// canonical ordering (stable sort of non-starter sequences)
+	for i := 0; i < len(ret); {
+		if n.combiningClasses[ret[i]] == 0 {
+			i++
+			continue
+		}
+		j := i
+		for j < len(ret) && n.combiningClasses[ret[j]] != 0 {
+			j++
+		}
+		slices.SortStableFunc(ret[i:j], func(a, b rune) int {
+			return n.combiningClasses[a] - n.combiningClasses[b]
+		})
+		i = j
+	}
+	return ret
+}
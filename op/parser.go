@@ -1,8 +1,18 @@
 package op
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
 	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/sekiguchi-nagisa/guniset/set"
 )
@@ -12,20 +22,29 @@ import (
 type TokenKind int
 
 const (
-	TokenEOS    TokenKind = iota // EOS
-	TokenId                      // identifier
-	TokenRune                    // codePoint
-	TokenColon                   // :
-	TokenComma                   // ,
-	TokenLParen                  // (
-	TokenRParen                  // )
-	TokenNegate                  // !
-	TokenPlus                    // +
-	TokenMinus                   // -
-	TokenMul                     // *
-	TokenAt                      // @
-	TokenRange                   // ..
-	TokenSpace                   // space
+	TokenEOS       TokenKind = iota // EOS
+	TokenId                         // identifier
+	TokenRune                       // codePoint
+	TokenColon                      // :
+	TokenComma                      // ,
+	TokenLParen                     // (
+	TokenRParen                     // )
+	TokenNegate                     // !
+	TokenPlus                       // +
+	TokenMinus                      // -
+	TokenMul                        // *
+	TokenAt                         // @
+	TokenRange                      // ..
+	TokenVar                        // variable
+	TokenAssign                     // =
+	TokenSemicolon                  // ;
+	TokenString                     // string
+	TokenRegex                      // regex
+	TokenVersion                    // version
+	TokenLessEq                     // <=
+	TokenRational                   // rational
+	TokenSpace                      // space
+	TokenComment                    // comment
 )
 
 type Lexeme struct {
@@ -34,6 +53,8 @@ type Lexeme struct {
 }
 
 var lexemes = []Lexeme{
+	{regexp.MustCompile(`^[0-9]+[.][0-9]+(?:[.][0-9]+)?`), TokenVersion},
+	{regexp.MustCompile(`^[0-9]+/[0-9]+`), TokenRational},
 	{regexp.MustCompile(`^U[+][0-9a-fA-F]+`), TokenRune},
 	{regexp.MustCompile(`^[0-9][0-9a-fA-F]*`), TokenRune},
 	{regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*`), TokenId},
@@ -46,65 +67,219 @@ var lexemes = []Lexeme{
 	{regexp.MustCompile(`^-`), TokenMinus},
 	{regexp.MustCompile(`^[*]`), TokenMul},
 	{regexp.MustCompile(`^[.][.]`), TokenRange},
-	{regexp.MustCompile(`^[ \t\n]+`), TokenSpace},
+	{regexp.MustCompile(`^[$][a-zA-Z_][a-zA-Z0-9_]*`), TokenVar},
+	{regexp.MustCompile(`^<=`), TokenLessEq},
+	{regexp.MustCompile(`^=`), TokenAssign},
+	{regexp.MustCompile(`^;`), TokenSemicolon},
+	{regexp.MustCompile(`^"(?:[^"\\\n]|\\.)*"`), TokenString},
+	{regexp.MustCompile(`^/(?:[^/\\\n]|\\.)*/`), TokenRegex},
+	{regexp.MustCompile(`^[ \t\r\n]+`), TokenSpace},
+	{regexp.MustCompile(`^#[^\n]*`), TokenComment},
 	{regexp.MustCompile(`^@`), TokenAt},
 }
 
+// Pos source position of token
+type Pos struct {
+	Offset int // byte offset (0-based)
+	Line   int // line number (1-based)
+	Col    int // column number in runes (1-based)
+}
+
+func (p Pos) advance(text string) Pos {
+	for _, r := range text {
+		p.Offset += utf8.RuneLen(r)
+		if r == '\n' {
+			p.Line++
+			p.Col = 1
+		} else {
+			p.Col++
+		}
+	}
+	return p
+}
+
 type Token struct {
 	kind TokenKind
 	text string
+	pos  Pos
+}
+
+// describe returns token kind description for error message
+func (k TokenKind) describe() string {
+	s := k.String()
+	switch {
+	case k == TokenEOS:
+		return "end of input"
+	case unicode.IsLetter([]rune(s)[0]):
+		return s
+	default:
+		return "'" + s + "'"
+	}
+}
+
+// SyntaxError syntax error with source position
+type SyntaxError struct {
+	FileName string // if empty, source is command line expression
+	Pos      Pos
+	Msg      string
+	Expected []TokenKind // expected token kinds at Pos (may be empty)
+	Source   []byte      // original source (if nil, not show source line)
+}
+
+func (e *SyntaxError) Error() string {
+	sb := strings.Builder{}
+	if e.FileName != "" {
+		sb.WriteString(fmt.Sprintf("%s:%d:%d: ", e.FileName, e.Pos.Line, e.Pos.Col))
+	}
+	sb.WriteString("[syntax error] ")
+	sb.WriteString(e.Msg)
+	if len(e.Expected) > 0 {
+		var expected []string
+		for _, kind := range e.Expected {
+			expected = append(expected, kind.describe())
+		}
+		last := len(expected) - 1
+		sb.WriteString(", expect: ")
+		if last > 0 {
+			sb.WriteString(strings.Join(expected[:last], ", "))
+			sb.WriteString(" or ")
+		}
+		sb.WriteString(expected[last])
+	}
+	if e.Source != nil && e.Pos.Offset <= len(e.Source) {
+		sb.WriteString("\n")
+		e.writeCaret(&sb)
+	}
+	return sb.String()
+}
+
+// writeCaret write source line of error position and caret under the offending token
+func (e *SyntaxError) writeCaret(sb *strings.Builder) {
+	lineStart := bytes.LastIndexByte(e.Source[:e.Pos.Offset], '\n') + 1
+	lineEnd := len(e.Source)
+	if i := bytes.IndexByte(e.Source[e.Pos.Offset:], '\n'); i > -1 {
+		lineEnd = e.Pos.Offset + i
+	}
+	line := strings.TrimRight(string(e.Source[lineStart:lineEnd]), "\r")
+	sb.WriteString(line)
+	sb.WriteString("\n")
+	for _, r := range string(e.Source[lineStart:e.Pos.Offset]) {
+		if r == '\t' { // keep alignment
+			sb.WriteRune('\t')
+		} else {
+			sb.WriteRune(' ')
+		}
+	}
+	sb.WriteRune('^')
+	width := 1
+	if tokens, _ := Tokenize(e.Source[e.Pos.Offset:lineEnd]); len(tokens) > 0 {
+		width = utf8.RuneCountInString(tokens[0].text)
+	}
+	sb.WriteString(strings.Repeat("~", width-1))
 }
 
 func Tokenize(src []byte) ([]Token, error) {
 	tokens := make([]Token, 0)
+	cur := Pos{Offset: 0, Line: 1, Col: 1}
 Next:
-	for pos := 0; pos < len(src); {
-		buf := src[pos:]
+	for cur.Offset < len(src) {
+		buf := src[cur.Offset:]
 		for _, lexeme := range lexemes {
 			r := lexeme.pattern.FindIndex(buf)
 			if r == nil {
 				continue
 			}
-			tokens = append(tokens, Token{lexeme.kind, string(src[pos : pos+r[1]])})
-			pos += r[1]
+			text := string(buf[:r[1]])
+			tokens = append(tokens, Token{lexeme.kind, text, cur})
+			cur = cur.advance(text)
 			continue Next
 		}
-		return tokens, fmt.Errorf("invalid token: %s", string(src[pos:]))
+		ch, _ := utf8.DecodeRune(buf)
+		return tokens, &SyntaxError{Pos: cur, Msg: fmt.Sprintf("invalid token: %q", ch), Source: src}
 	}
 	return tokens, nil
 }
 
 type Parser struct {
-	aliasMaps *AliasMapRecord
-	defRecord *DefRecord
-	tokens    []Token
-	pos       int
-	err       error
+	aliasMaps    *AliasMapRecord
+	defRecord    *DefRecord
+	fileName     string // currently parsing file name (empty if command line expression)
+	tokens       []Token
+	pos          int
+	eosPos       Pos // end of source position
+	expected     []TokenKind
+	expectedPos  int               // token index corresponding to expected
+	sources      map[string][]byte // file name to source (for error message)
+	err          error
+	bindings     map[string]*Binding
+	vars         []*VarNode
+	current      *Binding            // currently parsing binding
+	included     map[string]struct{} // already included files (absolute path)
+	includeStack []string            // for cyclic include detection
+	strict       bool                // if true, disable loose matching of property values
 }
 
 func NewParser(maps *AliasMapRecord, defRecord *DefRecord) *Parser {
 	return &Parser{aliasMaps: maps, defRecord: defRecord}
 }
 
-func syntaxErr(msg string) error {
-	return fmt.Errorf("[syntax error] %s", msg)
+// SetStrict if true, property values must be exactly matched (disable UAX44-LM3 loose matching)
+func (p *Parser) SetStrict(strict bool) *Parser {
+	p.strict = strict
+	return p
+}
+
+func (p *Parser) errorAt(fileName string, pos Pos, msg string) {
+	p.err = &SyntaxError{FileName: fileName, Pos: pos, Msg: msg, Source: p.sources[fileName]}
+	panic(p.err)
 }
 
 func (p *Parser) error(msg string) {
-	p.err = syntaxErr(msg)
+	p.errorAt(p.fileName, p.fetch().pos, msg)
+}
+
+// unexpected report unexpected token with expected token kinds at current position
+func (p *Parser) unexpected() {
+	token := p.fetch()
+	msg := "unexpected " + token.kind.describe()
+	if token.kind != TokenEOS && !strings.HasPrefix(msg, "unexpected '") { // also show text if not symbol
+		msg += ": " + token.text
+	}
+	p.err = &SyntaxError{FileName: p.fileName, Pos: token.pos, Msg: msg,
+		Expected: p.expectedKinds(), Source: p.sources[p.fileName]}
 	panic(p.err)
 }
 
+// addExpected record expected token kinds at current position
+func (p *Parser) addExpected(kinds ...TokenKind) {
+	if p.expectedPos != p.pos {
+		p.expected = p.expected[:0]
+		p.expectedPos = p.pos
+	}
+	for _, kind := range kinds {
+		if !slices.Contains(p.expected, kind) {
+			p.expected = append(p.expected, kind)
+		}
+	}
+}
+
+func (p *Parser) expectedKinds() []TokenKind {
+	if p.expectedPos != p.pos {
+		return nil
+	}
+	return slices.Clone(p.expected)
+}
+
 func (p *Parser) hasNext() bool {
 	return p.pos < len(p.tokens)
 }
 
+// fetch returns current token. if reach end of input, returns TokenEOS
 func (p *Parser) fetch() Token {
 	if p.hasNext() {
 		return p.tokens[p.pos]
 	}
-	p.error("unexpected end of token")
-	return Token{TokenEOS, ""}
+	return Token{TokenEOS, "", p.eosPos}
 }
 
 func (p *Parser) consume() {
@@ -113,238 +288,574 @@ func (p *Parser) consume() {
 }
 
 func (p *Parser) skipSpace() {
-	for p.hasNext() && p.tokens[p.pos].kind == TokenSpace {
+	for p.hasNext() && (p.tokens[p.pos].kind == TokenSpace || p.tokens[p.pos].kind == TokenComment) {
 		p.pos++
 	}
 }
 
-func (p *Parser) expect(kind TokenKind) Token {
-	token := p.fetch()
-	if token.kind != kind {
-		p.error(fmt.Sprintf("token mismatched, expect: %s, actual: %s", kind.String(),
-			token.kind.String()))
+// check returns true if current token kind is kind (not consume)
+func (p *Parser) check(kind TokenKind) bool {
+	p.addExpected(kind)
+	return p.fetch().kind == kind
+}
+
+// peek returns current token if its kind is kind (not consume)
+func (p *Parser) peek(kind TokenKind) Token {
+	if !p.check(kind) {
+		p.unexpected()
 	}
+	return p.fetch()
+}
+
+func (p *Parser) expect(kind TokenKind) Token {
+	token := p.peek(kind)
 	p.consume()
 	return token
 }
 
-func (p *Parser) Run(src []byte) (node Node, err error) {
+// enter start parsing new source
+func (p *Parser) enter(fileName string, src []byte) {
 	tokens, err := Tokenize(src)
 	if err != nil {
-		return nil, syntaxErr(err.Error())
+		var syntaxError *SyntaxError
+		if errors.As(err, &syntaxError) {
+			syntaxError.FileName = fileName
+		}
+		p.err = err
+		panic(p.err)
 	}
+	p.fileName = fileName
 	p.tokens = tokens
 	p.pos = 0
+	p.expectedPos = -1
+	p.sources[fileName] = src
+	p.eosPos = Pos{Offset: 0, Line: 1, Col: 1}.advance(string(src))
+	p.skipSpace()
+}
+
+// Run parse set operation
+func (p *Parser) Run(src []byte) (Node, error) {
+	return p.run("", src)
+}
+
+// RunFile parse set operation file
+func (p *Parser) RunFile(fileName string) (Node, error) {
+	src, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	return p.run(fileName, src)
+}
+
+func (p *Parser) run(fileName string, src []byte) (node Node, err error) {
 	p.err = nil
+	p.bindings = map[string]*Binding{}
+	p.vars = nil
+	p.current = nil
+	p.included = map[string]struct{}{}
+	p.includeStack = nil
+	p.sources = map[string][]byte{}
+	if fileName != "" {
+		if abs, err := filepath.Abs(fileName); err == nil {
+			p.included[abs] = struct{}{}
+			p.includeStack = append(p.includeStack, abs)
+		}
+	}
 	defer func() {
-		recover()
+		if r := recover(); r != nil && p.err == nil { // not syntax error
+			panic(r)
+		}
 		err = p.err
 	}()
-	p.skipSpace()
-	node = p.parseUnionOrDiff()
-	if p.hasNext() {
-		p.error(fmt.Sprintf("unexpected token: %s", p.fetch().kind.String()))
+	p.enter(fileName, src)
+	node = p.parseProgram()
+	if !p.check(TokenEOS) {
+		p.unexpected()
 	}
+	p.resolveBindings()
 	if p.err != nil {
 		return nil, err
 	}
 	return node, nil
 }
 
-func (p *Parser) parsePropertySeq(consumer func(string)) {
+const (
+	letKeyword     = "let"
+	includeKeyword = "include"
+)
+
+func (p *Parser) isKeyword(keyword string) bool {
+	return p.hasNext() && p.fetch().kind == TokenId && p.fetch().text == keyword
+}
+
+// parseStatements parse let and include statements
+func (p *Parser) parseStatements() {
+	for {
+		switch {
+		case p.isKeyword(letKeyword):
+			p.parseLet()
+		case p.isKeyword(includeKeyword):
+			p.parseInclude()
+		default:
+			return
+		}
+	}
+}
+
+func (p *Parser) parseProgram() Node {
+	p.parseStatements()
+	return p.parseUnionOrDiff()
+}
+
+func (p *Parser) parseLet() {
+	p.consume()
 	token := p.expect(TokenId)
-	consumer(token.text)
-	for p.hasNext() && p.fetch().kind == TokenComma {
+	name := token.text
+	if _, ok := p.bindings[name]; ok {
+		p.errorAt(p.fileName, token.pos, fmt.Sprintf("already defined: $%s", name))
+	}
+	binding := &Binding{name: name, fileName: p.fileName, pos: token.pos}
+	p.bindings[name] = binding
+	p.expect(TokenAssign)
+	p.current = binding
+	binding.node = p.parseUnionOrDiff()
+	p.current = nil
+	p.expect(TokenSemicolon)
+}
+
+func (p *Parser) parseInclude() {
+	p.consume()
+	token := p.expect(TokenString)
+	target, err := strconv.Unquote(token.text)
+	if err != nil {
+		p.errorAt(p.fileName, token.pos, fmt.Sprintf("invalid string: %s", token.text))
+	}
+	if !filepath.IsAbs(target) { // resolve relative to including file
+		target = filepath.Join(filepath.Dir(p.fileName), target)
+	}
+	abs, err := filepath.Abs(target)
+	if err != nil {
+		p.errorAt(p.fileName, token.pos, fmt.Sprintf("cannot include %s: %s", target, err.Error()))
+	}
+	if slices.Contains(p.includeStack, abs) {
+		p.errorAt(p.fileName, token.pos, fmt.Sprintf("cyclic include: %s", target))
+	}
+	if _, ok := p.included[abs]; ok { // already included
+		return
+	}
+	src, err := os.ReadFile(target)
+	if err != nil {
+		p.errorAt(p.fileName, token.pos, fmt.Sprintf("cannot include %s: %s", target, err.Error()))
+	}
+	p.included[abs] = struct{}{}
+
+	// parse included file (only allow statements)
+	fileName, tokens, pos, eosPos := p.fileName, p.tokens, p.pos, p.eosPos
+	p.includeStack = append(p.includeStack, abs)
+	p.enter(target, src)
+	p.parseStatements()
+	if p.hasNext() {
+		p.error(fmt.Sprintf("unexpected %s, included file must only have `let` or `include`",
+			p.fetch().kind.describe()))
+	}
+	p.includeStack = p.includeStack[:len(p.includeStack)-1]
+	p.fileName, p.tokens, p.pos, p.eosPos = fileName, tokens, pos, eosPos
+}
+
+func (p *Parser) resolveBindings() {
+	for _, v := range p.vars {
+		binding, ok := p.bindings[v.name]
+		if !ok {
+			p.errorAt(v.fileName, v.pos, fmt.Sprintf("undefined variable: $%s", v.name))
+		}
+		v.binding = binding
+	}
+
+	// detect cyclic definition
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	states := map[*Binding]int{}
+	var path []string
+	var visit func(binding *Binding)
+	visit = func(binding *Binding) {
+		switch states[binding] {
+		case visiting:
+			path = append(path, "$"+binding.name)
+			p.errorAt(binding.fileName, binding.pos, fmt.Sprintf("cyclic definition: %s", strings.Join(path, " -> ")))
+		case visited:
+			return
+		}
+		states[binding] = visiting
+		path = append(path, "$"+binding.name)
+		for _, ref := range binding.refs {
+			visit(ref.binding)
+		}
+		path = path[:len(path)-1]
+		states[binding] = visited
+	}
+	for _, name := range slices.Sorted(maps.Keys(p.bindings)) {
+		visit(p.bindings[name])
+	}
+}
+
+// parsePropertySeq parse comma separated property values.
+// consumer is called before consuming each value token (error points to the value)
+func (p *Parser) parsePropertySeq(consumer func(string)) {
+	consumer(p.peekPropertyValue())
+	p.consume()
+	for p.check(TokenComma) {
+		p.consume()
+		consumer(p.peekPropertyValue())
 		p.consume()
-		token = p.expect(TokenId)
-		consumer(token.text)
 	}
 }
 
+// peekPropertyValue returns property value of identifier or string (for names including spaces or hyphens)
+func (p *Parser) peekPropertyValue() string {
+	// allow negative numeric value such as `nv:-1/2` (sign must be followed by number without spaces)
+	if p.fetch().kind == TokenMinus && p.pos+1 < len(p.tokens) {
+		if next := p.tokens[p.pos+1]; next.kind == TokenRune || next.kind == TokenRational {
+			p.pos++ // not skip spaces
+			return "-" + next.text
+		}
+	}
+	// allow numeric value such as `ccc:230`, `age:15.0`, `nv:1/2`
+	if p.check(TokenId) || p.check(TokenRune) || p.check(TokenVersion) || p.check(TokenRational) {
+		text := p.fetch().text
+		// allow hyphenated value such as `dcp:ID-Start` (hyphens must not be surrounded by spaces)
+		for p.pos+2 < len(p.tokens) && p.tokens[p.pos+1].kind == TokenMinus && p.tokens[p.pos+2].kind == TokenId {
+			// `gc:Lu-gc:Ll` is still a difference of two properties
+			if p.pos+3 < len(p.tokens) && p.tokens[p.pos+3].kind == TokenColon {
+				break
+			}
+			p.pos += 2 // not skip spaces
+			text += "-" + p.tokens[p.pos].text
+		}
+		return text
+	}
+	s, err := strconv.Unquote(p.peek(TokenString).text)
+	if err != nil {
+		p.error(fmt.Sprintf("invalid string: %s", p.fetch().text))
+	}
+	return s
+}
+
 func (p *Parser) parseRune() rune {
-	s := p.expect(TokenRune).text
-	r, err := set.ParseRune(s)
+	r, err := set.ParseRune(p.peek(TokenRune).text)
 	if err != nil {
 		p.error(err.Error())
 	}
+	p.consume()
 	return r
 }
 
-func (p *Parser) parsePrimary() Node {
-	switch curKind := p.fetch().kind; curKind {
-	case TokenId:
-		prefix := p.expect(TokenId)
-		if IsGeneralCategoryPrefix(prefix.text) {
-			p.expect(TokenColon)
+// prefixParser parser of property values under prefix
+type prefixParser struct {
+	prefix string
+	match  func(string) bool // if true, same property prefix
+	parse  func(string) error
+	node   func(prefix string) Node // parse comma separated property values after `prefix:`
+}
+
+func parseBy[T any](parse func(string) (T, error)) func(string) error {
+	return func(s string) error {
+		_, err := parse(s)
+		return err
+	}
+}
+
+// newPropertyParser returns prefixParser of property defined by PropertyDef. aliasMap may be nil
+func newPropertyParser[T ~int](p *Parser, prefix string, match func(string) bool, def *PropertyDef[T],
+	aliasMap *AliasMap, setMap func(*EvalContext) UniSetMap[T]) prefixParser {
+	parse := func(s string) (T, error) {
+		return def.ParseWithAlias(s, aliasMap)
+	}
+	return prefixParser{prefix, match, parseBy(parse), func(prefix string) Node {
+		var properties []T
+		p.parsePropertySeq(func(s string) {
+			v, err := parse(s)
+			if err != nil {
+				p.valueError(prefix, s, err)
+			}
+			if aliasMap == nil {
+				p.checkStrict("property", s, def.GetName(v))
+			} else {
+				p.checkStrict("property", s, def.Names(v, aliasMap)...)
+			}
+			properties = append(properties, v)
+		})
+		return NewPropertyNode(properties, func(ctx *EvalContext, v T) (*set.UniSet, bool) {
+			s, k := setMap(ctx)[v]
+			return s, k
+		})
+	}}
+}
+
+func (p *Parser) prefixParsers() []prefixParser {
+	parsers := []prefixParser{
+		{GeneralCategoryPrefix, IsGeneralCategoryPrefix, parseBy(func(s string) (GeneralCategory, error) {
+			return ParseGeneralCategory(s, p.aliasMaps.Category())
+		}), func(prefix string) Node {
 			var properties []GeneralCategory
 			p.parsePropertySeq(func(s string) {
 				v, err := ParseGeneralCategory(s, p.aliasMaps.Category())
 				if err != nil {
-					p.error(err.Error())
+					p.valueError(prefix, s, err)
 				}
+				p.checkStrict("general category", s, v.Names(p.aliasMaps.Category())...)
 				properties = append(properties, v)
 			})
 			return NewGeneralCategoryNode(properties)
-		} else if IsEastAsianWidthPrefix(prefix.text) {
-			p.expect(TokenColon)
+		}},
+		{EastAsianWidthPrefix, IsEastAsianWidthPrefix, parseBy(func(s string) (EastAsianWidth, error) {
+			return ParseEastAsianWidth(s, p.aliasMaps.Eaw())
+		}), func(prefix string) Node {
 			var properties []EastAsianWidth
 			p.parsePropertySeq(func(s string) {
 				v, err := ParseEastAsianWidth(s, p.aliasMaps.Eaw())
 				if err != nil {
-					p.error(err.Error())
+					p.valueError(prefix, s, err)
 				}
+				p.checkStrict("east asian width", s, v.Names(p.aliasMaps.Eaw())...)
 				properties = append(properties, v)
 			})
 			return NewEastAsianWidthNode(properties)
-		} else if (IsScriptPrefix(prefix.text) || IsScriptExtensionPrefix(prefix.text)) && p.defRecord != nil {
-			p.expect(TokenColon)
+		}},
+	}
+	if p.defRecord == nil {
+		return parsers
+	}
+	defs := p.defRecord
+	age := newPropertyParser(p, AgePrefix, IsAgePrefix, defs.AgeDef, p.aliasMaps.Age(),
+		func(ctx *EvalContext) UniSetMap[Age] { return ctx.AgeMap })
+	parseAge := age.node
+	age.node = func(prefix string) Node {
+		if p.check(TokenLessEq) { // age:<=13.0
+			p.consume()
+			return p.parseAgeUpTo()
+		}
+		return parseAge(prefix)
+	}
+	return append(parsers,
+		prefixParser{ScriptPrefix, func(s string) bool {
+			return IsScriptPrefix(s) || IsScriptExtensionPrefix(s)
+		}, parseBy(func(s string) (Script, error) {
+			return defs.ScriptDef.Parse(s, p.aliasMaps.Script())
+		}), func(prefix string) Node {
 			var properties []Script
 			p.parsePropertySeq(func(s string) {
-				v, err := p.defRecord.ScriptDef.Parse(s, p.aliasMaps.Script())
+				v, err := defs.ScriptDef.Parse(s, p.aliasMaps.Script())
 				if err != nil {
-					p.error(err.Error())
+					p.valueError(prefix, s, err)
 				}
+				p.checkStrict("script", s, defs.ScriptDef.Names(v, p.aliasMaps.Script())...)
 				properties = append(properties, v)
 			})
-			if IsScriptExtensionPrefix(prefix.text) {
+			if IsScriptExtensionPrefix(prefix) {
 				return NewScriptXNode(properties)
 			}
 			return NewScriptNode(properties)
-		} else if IsPropListPrefix(prefix.text) && p.defRecord != nil {
-			p.expect(TokenColon)
-			var properties []PropList
-			p.parsePropertySeq(func(s string) {
-				v, err := p.defRecord.PropListDef.Parse(s)
-				if err != nil {
-					p.error(err.Error())
-				}
-				properties = append(properties, v)
-			})
-			return NewPropertyNode(properties, func(ctx *EvalContext, p PropList) (*set.UniSet, bool) {
-				s, k := ctx.PropListMap[p]
-				return s, k
-			})
-		} else if IsDerivedCorePropertyPrefix(prefix.text) && p.defRecord != nil {
-			p.expect(TokenColon)
-			var properties []DerivedCoreProperty
-			p.parsePropertySeq(func(s string) {
-				v, err := p.defRecord.DerivedCorePropDef.Parse(s)
-				if err != nil {
-					p.error(err.Error())
-				}
-				properties = append(properties, v)
-			})
-			return NewPropertyNode(properties, func(ctx *EvalContext, p DerivedCoreProperty) (*set.UniSet, bool) {
-				s, k := ctx.DerivedCorePropMap[p]
-				return s, k
-			})
-		} else if IsEmojiPrefix(prefix.text) && p.defRecord != nil {
-			p.expect(TokenColon)
-			var properties []Emoji
-			p.parsePropertySeq(func(s string) {
-				v, err := p.defRecord.EmojiDef.Parse(s)
-				if err != nil {
-					p.error(err.Error())
-				}
-				properties = append(properties, v)
-			})
-			return NewPropertyNode(properties, func(ctx *EvalContext, p Emoji) (*set.UniSet, bool) {
-				s, k := ctx.EmojiMap[p]
-				return s, k
-			})
-		} else if IsDerivedBinaryPropertyPrefix(prefix.text) && p.defRecord != nil {
-			p.expect(TokenColon)
-			var properties []DerivedBinaryProperty
-			p.parsePropertySeq(func(s string) {
-				v, err := p.defRecord.DerivedBinaryPropDef.Parse(s)
-				if err != nil {
-					p.error(err.Error())
-				}
-				properties = append(properties, v)
-			})
-			return NewPropertyNode(properties, func(ctx *EvalContext, p DerivedBinaryProperty) (*set.UniSet, bool) {
-				s, k := ctx.DerivedBinaryPropMap[p]
-				return s, k
-			})
-		} else if IsDerivedNormalizationPropPrefix(prefix.text) && p.defRecord != nil {
-			p.expect(TokenColon)
-			var properties []DerivedNormalizationProp
-			p.parsePropertySeq(func(s string) {
-				v, err := p.defRecord.DerivedNormalizationPropDef.Parse(s)
-				if err != nil {
-					p.error(err.Error())
-				}
-				properties = append(properties, v)
-			})
-			return NewPropertyNode(properties, func(ctx *EvalContext, p DerivedNormalizationProp) (*set.UniSet, bool) {
-				s, k := ctx.DerivedNormalizationPropMap[p]
-				return s, k
-			})
-		} else if IsGraphemeBreakPropertyPrefix(prefix.text) && p.defRecord != nil {
-			p.expect(TokenColon)
-			var properties []GraphemeBreakProperty
-			p.parsePropertySeq(func(s string) {
-				v, err := p.defRecord.GraphemeBreakPropDef.Parse(s)
-				if err != nil {
-					p.error(err.Error())
-				}
-				properties = append(properties, v)
-			})
-			return NewPropertyNode(properties, func(ctx *EvalContext, p GraphemeBreakProperty) (*set.UniSet, bool) {
-				s, k := ctx.GraphemeBreakPropMap[p]
-				return s, k
-			})
-		} else if IsWordBreakPropertyPrefix(prefix.text) && p.defRecord != nil {
-			p.expect(TokenColon)
-			var properties []WordBreakProperty
-			p.parsePropertySeq(func(s string) {
-				v, err := p.defRecord.WordBreakPropDef.Parse(s)
-				if err != nil {
-					p.error(err.Error())
-				}
-				properties = append(properties, v)
-			})
-			return NewPropertyNode(properties, func(ctx *EvalContext, p WordBreakProperty) (*set.UniSet, bool) {
-				s, k := ctx.WordBreakPropMap[p]
-				return s, k
-			})
-		} else if IsSentenceBreakPropertyPrefix(prefix.text) && p.defRecord != nil {
+		}},
+		newPropertyParser(p, PropListPrefix, IsPropListPrefix, defs.PropListDef, nil,
+			func(ctx *EvalContext) UniSetMap[PropList] { return ctx.PropListMap }),
+		newPropertyParser(p, DerivedCorePropPrefix, IsDerivedCorePropertyPrefix, defs.DerivedCorePropDef, nil,
+			func(ctx *EvalContext) UniSetMap[DerivedCoreProperty] { return ctx.DerivedCorePropMap }),
+		newPropertyParser(p, EmojiPrefix, IsEmojiPrefix, defs.EmojiDef, nil,
+			func(ctx *EvalContext) UniSetMap[Emoji] { return ctx.EmojiMap }),
+		newPropertyParser(p, DerivedBinaryPropPrefix, IsDerivedBinaryPropertyPrefix, defs.DerivedBinaryPropDef, nil,
+			func(ctx *EvalContext) UniSetMap[DerivedBinaryProperty] { return ctx.DerivedBinaryPropMap }),
+		newPropertyParser(p, DerivedNormalizationPropPrefix, IsDerivedNormalizationPropPrefix, defs.DerivedNormalizationPropDef, nil,
+			func(ctx *EvalContext) UniSetMap[DerivedNormalizationProp] { return ctx.DerivedNormalizationPropMap }),
+		newPropertyParser(p, GraphemeBreakPropPrefix, IsGraphemeBreakPropertyPrefix, defs.GraphemeBreakPropDef, nil,
+			func(ctx *EvalContext) UniSetMap[GraphemeBreakProperty] { return ctx.GraphemeBreakPropMap }),
+		newPropertyParser(p, WordBreakPropPrefix, IsWordBreakPropertyPrefix, defs.WordBreakPropDef, nil,
+			func(ctx *EvalContext) UniSetMap[WordBreakProperty] { return ctx.WordBreakPropMap }),
+		newPropertyParser(p, SentenceBreakPropPrefix, IsSentenceBreakPropertyPrefix, defs.SentenceBreakPropDef, nil,
+			func(ctx *EvalContext) UniSetMap[SentenceBreakProperty] { return ctx.SentenceBreakPropMap }),
+		newPropertyParser(p, IndicSyllabicCategoryPrefix, IsIndicSyllabicCategoryPrefix, defs.IndicSyllabicCategoryDef, nil,
+			func(ctx *EvalContext) UniSetMap[IndicSyllabicCategory] { return ctx.IndicSyllabicCategoryMap }),
+		newPropertyParser(p, IndicPositionalCategoryPrefix, IsIndicPositionalCategoryPrefix, defs.IndicPositionalCategoryDef, nil,
+			func(ctx *EvalContext) UniSetMap[IndicPositionalCategory] { return ctx.IndicPositionalCategoryMap }),
+		newPropertyParser(p, VerticalOrientationPrefix, IsVerticalOrientationPrefix, defs.VerticalOrientationDef,
+			p.aliasMaps.VerticalOrientation(),
+			func(ctx *EvalContext) UniSetMap[VerticalOrientation] { return ctx.VerticalOrientationMap }),
+		newPropertyParser(p, HangulSyllableTypePrefix, IsHangulSyllableTypePrefix, defs.HangulSyllableTypeDef,
+			p.aliasMaps.HangulSyllableType(),
+			func(ctx *EvalContext) UniSetMap[HangulSyllableType] { return ctx.HangulSyllableTypeMap }),
+		newPropertyParser(p, BidiPairedBracketTypePrefix, IsBidiPairedBracketTypePrefix, defs.BidiPairedBracketTypeDef,
+			p.aliasMaps.BidiPairedBracketType(),
+			func(ctx *EvalContext) UniSetMap[BidiPairedBracketType] { return ctx.BidiPairedBracketTypeMap }),
+		newPropertyParser(p, LineBreakPrefix, IsLineBreakPrefix, defs.LineBreakDef, p.aliasMaps.LineBreak(),
+			func(ctx *EvalContext) UniSetMap[LineBreak] { return ctx.LineBreakMap }),
+		newPropertyParser(p, BidiClassPrefix, IsBidiClassPrefix, defs.BidiClassDef, p.aliasMaps.BidiClass(),
+			func(ctx *EvalContext) UniSetMap[BidiClass] { return ctx.BidiClassMap }),
+		newPropertyParser(p, CombiningClassPrefix, IsCombiningClassPrefix, defs.CombiningClassDef, p.aliasMaps.CombiningClass(),
+			func(ctx *EvalContext) UniSetMap[CombiningClass] { return ctx.CombiningClassMap }),
+		newPropertyParser(p, DecompositionTypePrefix, IsDecompositionTypePrefix, defs.DecompositionTypeDef,
+			p.aliasMaps.DecompositionType(),
+			func(ctx *EvalContext) UniSetMap[DecompositionType] { return ctx.DecompositionTypeMap }),
+		age,
+		newPropertyParser(p, BlockPrefix, IsBlockPrefix, defs.BlockDef, p.aliasMaps.Block(),
+			func(ctx *EvalContext) UniSetMap[Block] { return ctx.BlockMap }),
+		newPropertyParser(p, JoiningTypePrefix, IsJoiningTypePrefix, defs.JoiningTypeDef, p.aliasMaps.JoiningType(),
+			func(ctx *EvalContext) UniSetMap[JoiningType] { return ctx.JoiningTypeMap }),
+		newPropertyParser(p, JoiningGroupPrefix, IsJoiningGroupPrefix, defs.JoiningGroupDef, p.aliasMaps.JoiningGroup(),
+			func(ctx *EvalContext) UniSetMap[JoiningGroup] { return ctx.JoiningGroupMap }),
+		newPropertyParser(p, NumericTypePrefix, IsNumericTypePrefix, defs.NumericTypeDef, p.aliasMaps.NumericType(),
+			func(ctx *EvalContext) UniSetMap[NumericType] { return ctx.NumericTypeMap }),
+		newPropertyParser(p, NumericValuePrefix, IsNumericValuePrefix, defs.NumericValueDef, nil,
+			func(ctx *EvalContext) UniSetMap[NumericValue] { return ctx.NumericValueMap }),
+		newPropertyParser(p, IdentifierStatusPrefix, IsIdentifierStatusPrefix, defs.IdentifierStatusDef, nil,
+			func(ctx *EvalContext) UniSetMap[IdentifierStatus] { return ctx.IdentifierStatusMap }),
+		newPropertyParser(p, IdentifierTypePrefix, IsIdentifierTypePrefix, defs.IdentifierTypeDef, nil,
+			func(ctx *EvalContext) UniSetMap[IdentifierType] { return ctx.IdentifierTypeMap }),
+	)
+}
+
+// checkStrict in strict matching mode, report error if s is not exactly one of names
+func (p *Parser) checkStrict(kind string, s string, names ...string) {
+	if p.strict && !slices.Contains(names, s) {
+		p.error(newUnknownPropertyValueError(kind, s, names).Error())
+	}
+}
+
+// valueError report unknown property value.
+// if value is defined under other prefixes, suggest them instead of similar values
+func (p *Parser) valueError(prefix string, value string, err error) {
+	var valueError *UnknownPropertyValueError
+	if errors.As(err, &valueError) {
+		var candidates []string
+		for _, parser := range p.prefixParsers() {
+			if !parser.match(prefix) && parser.parse(value) == nil {
+				candidates = append(candidates, parser.prefix+":"+value)
+			}
+		}
+		if len(candidates) > 0 {
+			valueError.Candidates = candidates
+		}
+	}
+	p.error(err.Error())
+}
+
+func (p *Parser) parsePrimary() Node {
+	p.addExpected(TokenId, TokenRune, TokenVar, TokenLParen)
+	switch p.fetch().kind {
+	case TokenId:
+		prefix := p.expect(TokenId)
+		if IsNamePrefix(prefix.text) {
 			p.expect(TokenColon)
-			var properties []SentenceBreakProperty
-			p.parsePropertySeq(func(s string) {
-				v, err := p.defRecord.SentenceBreakPropDef.Parse(s)
-				if err != nil {
-					p.error(err.Error())
-				}
-				properties = append(properties, v)
-			})
-			return NewPropertyNode(properties, func(ctx *EvalContext, p SentenceBreakProperty) (*set.UniSet, bool) {
-				s, k := ctx.SentenceBreakPropMap[p]
-				return s, k
-			})
-		} else {
-			p.error(UnknowPropertyPrefixError(prefix.text))
+			return p.parseNamePattern()
 		}
+		for _, parser := range p.prefixParsers() {
+			if parser.match(prefix.text) {
+				p.expect(TokenColon)
+				return parser.node(prefix.text)
+			}
+		}
+		p.errorAt(p.fileName, prefix.pos, UnknowPropertyPrefixError(prefix.text))
 	case TokenRune:
 		first := p.parseRune()
 		last := first
-		if p.hasNext() && p.fetch().kind == TokenRange {
+		if p.check(TokenRange) {
 			p.consume()
 			last = p.parseRune()
 		}
 		return &RangeNode{runeRange: set.RuneRange{First: first, Last: last}}
+	case TokenVar:
+		token := p.expect(TokenVar)
+		node := &VarNode{name: strings.TrimPrefix(token.text, "$"), fileName: p.fileName, pos: token.pos}
+		p.vars = append(p.vars, node)
+		if p.current != nil {
+			p.current.refs = append(p.current.refs, node)
+		}
+		return node
 	case TokenLParen:
 		p.consume()
 		node := p.parseUnionOrDiff()
 		p.expect(TokenRParen)
 		return node
 	default:
-		p.error(fmt.Sprintf("unknown token: %s", curKind.String()))
+		p.unexpected()
 	}
 	return nil
 }
 
+// parseAgeUpTo parse version and returns code points assigned in or before it (age:<=13.0, @since(13.0))
+func (p *Parser) parseAgeUpTo() Node {
+	ages, err := AgesUpTo(p.defRecord.AgeDef, p.peekPropertyValue(), p.aliasMaps.Age())
+	if err != nil {
+		p.error(err.Error())
+	}
+	p.consume()
+	return NewPropertyNode(ages, func(ctx *EvalContext, p Age) (*set.UniSet, bool) {
+		s, k := ctx.AgeMap[p]
+		return s, k
+	})
+}
+
+// parseNamePattern parse regex (/ARROW/) or wildcard ("*DASH*") of character name
+func (p *Parser) parseNamePattern() Node {
+	p.addExpected(TokenRegex, TokenString)
+	token := p.fetch()
+	var pattern *regexp.Regexp
+	var err error
+	switch token.kind {
+	case TokenRegex:
+		p.consume()
+		src := token.text[1 : len(token.text)-1]
+		pattern, err = regexp.Compile(strings.ReplaceAll(src, `\/`, "/"))
+	case TokenString:
+		p.consume()
+		var s string
+		s, err = strconv.Unquote(token.text)
+		if err == nil {
+			pattern, err = WildcardToRegexp(s)
+		}
+	default:
+		p.unexpected()
+	}
+	if err != nil {
+		p.errorAt(p.fileName, token.pos, fmt.Sprintf("invalid name pattern: %s", err.Error()))
+	}
+	return &NameNode{pattern: pattern}
+}
+
+// parseFoldMode parse optional case folding mode (`, simple` or `, full`). if full, return true
+func (p *Parser) parseFoldMode() bool {
+	if !p.check(TokenComma) {
+		return false
+	}
+	p.consume()
+	token := p.expect(TokenId)
+	switch token.text {
+	case "simple":
+		return false
+	case "full":
+		return true
+	default:
+		p.errorAt(p.fileName, token.pos, fmt.Sprintf("unknown case folding mode: %s, must be `simple` or `full`", token.text))
+	}
+	return false
+}
+
+// parseClosureMode parse optional case closure mode (`, turkic`). if turkic, return true
+func (p *Parser) parseClosureMode() bool {
+	if !p.check(TokenComma) {
+		return false
+	}
+	p.consume()
+	token := p.expect(TokenId)
+	if token.text != "turkic" {
+		p.errorAt(p.fileName, token.pos, fmt.Sprintf("unknown case closure mode: %s, must be `turkic`", token.text))
+	}
+	return true
+}
+
 func (p *Parser) parseComplement() Node {
+	p.addExpected(TokenNegate, TokenAt)
 	switch p.fetch().kind {
 	case TokenNegate:
 		p.consume()
@@ -358,44 +869,70 @@ func (p *Parser) parseComplement() Node {
 	}
 }
 
+var caseMappingFuncs = map[string]CaseMappingKind{
+	"upper": CaseMappingUpper,
+	"lower": CaseMappingLower,
+	"title": CaseMappingTitle,
+}
+
 func (p *Parser) parseFunc() Node {
 	token := p.expect(TokenId)
 	p.expect(TokenLParen)
 	switch token.text {
 	case "fold":
 		node := p.parseUnionOrDiff()
+		full := p.parseFoldMode()
 		p.expect(TokenRParen)
+		if full {
+			return &CaseMappingNode{node, CaseMappingFullFold}
+		}
 		return &CaseFoldNode{node}
 	case "unfold":
 		node := p.parseUnionOrDiff()
 		p.expect(TokenRParen)
 		return &CaseUnfoldNode{node}
+	case "closure":
+		node := p.parseUnionOrDiff()
+		turkic := p.parseClosureMode()
+		p.expect(TokenRParen)
+		return &CaseClosureNode{node, turkic}
+	case "upper", "lower", "title":
+		node := p.parseUnionOrDiff()
+		p.expect(TokenRParen)
+		return &CaseMappingNode{node, caseMappingFuncs[token.text]}
+	case "mirror":
+		node := p.parseUnionOrDiff()
+		p.expect(TokenRParen)
+		return &MirrorNode{node}
+	case "since":
+		node := p.parseAgeUpTo()
+		p.expect(TokenRParen)
+		return node
+	case "name":
+		node := p.parseNamePattern()
+		p.expect(TokenRParen)
+		return node
 	default:
-		p.error(fmt.Sprintf("unknown function: %s", token.text))
+		p.errorAt(p.fileName, token.pos, fmt.Sprintf("unknown function: %s", token.text))
 	}
 	return nil
 }
 
 func (p *Parser) parseIntersect() Node {
 	left := p.parseComplement()
-	for p.hasNext() {
-		switch curKind := p.fetch().kind; curKind {
-		case TokenMul:
-			p.consume()
-			right := p.parseComplement()
-			left = &IntersectNode{left, right}
-			continue
-		default:
-		}
-		break
+	for p.check(TokenMul) {
+		p.consume()
+		right := p.parseComplement()
+		left = &IntersectNode{left, right}
 	}
 	return left
 }
 
 func (p *Parser) parseUnionOrDiff() Node {
 	left := p.parseIntersect()
-	for p.hasNext() {
-		switch curKind := p.fetch().kind; curKind {
+	for {
+		p.addExpected(TokenPlus, TokenMinus)
+		switch p.fetch().kind {
 		case TokenPlus:
 			p.consume()
 			right := p.parseIntersect()
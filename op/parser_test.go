@@ -1,51 +1,101 @@
 package op
 
 import (
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/sekiguchi-nagisa/guniset/set"
 	"github.com/stretchr/testify/assert"
 )
 
+type lexToken struct { // token without position
+	kind TokenKind
+	text string
+}
+
 var lexerTestCases = []struct {
 	src    string
-	tokens []Token
+	tokens []lexToken
 }{
-	{"1234", []Token{{TokenRune, "1234"}}},
-	{" 1234s", []Token{{TokenSpace, " "}, {TokenRune, "1234"}, {TokenId, "s"}}},
-	{"1234+  cat:eee, five ", []Token{
+	{"1234", []lexToken{{TokenRune, "1234"}}},
+	{" 1234s", []lexToken{{TokenSpace, " "}, {TokenRune, "1234"}, {TokenId, "s"}}},
+	{"1234+  cat:eee, five ", []lexToken{
 		{TokenRune, "1234"}, {TokenPlus, "+"},
 		{TokenSpace, "  "}, {TokenId, "cat"},
 		{TokenColon, ":"}, {TokenId, "eee"},
 		{TokenComma, ","}, {TokenSpace, " "},
 		{TokenId, "five"}, {TokenSpace, " "},
 	}},
-	{"0..U+f", []Token{{TokenRune, "0"}, {TokenRange, ".."}, {TokenRune, "U+f"}}},
-	{"0..f", []Token{{TokenRune, "0"}, {TokenRange, ".."}, {TokenId, "f"}}},
-	{"0..0f", []Token{{TokenRune, "0"}, {TokenRange, ".."}, {TokenRune, "0f"}}},
-	{"-124", []Token{{TokenMinus, "-"}, {TokenRune, "124"}}},
-	{"U+(455)", []Token{{TokenId, "U"}, {TokenPlus, "+"},
+	{"0..U+f", []lexToken{{TokenRune, "0"}, {TokenRange, ".."}, {TokenRune, "U+f"}}},
+	{"0..f", []lexToken{{TokenRune, "0"}, {TokenRange, ".."}, {TokenId, "f"}}},
+	{"0..0f", []lexToken{{TokenRune, "0"}, {TokenRange, ".."}, {TokenRune, "0f"}}},
+	{"-124", []lexToken{{TokenMinus, "-"}, {TokenRune, "124"}}},
+	{"U+(455)", []lexToken{{TokenId, "U"}, {TokenPlus, "+"},
 		{TokenLParen, "("}, {TokenRune, "455"}, {TokenRParen, ")"}}},
-	{"cat:Cn * eaw:F", []Token{
+	{"cat:Cn * eaw:F", []lexToken{
 		{TokenId, "cat"}, {TokenColon, ":"},
 		{TokenId, "Cn"}, {TokenSpace, " "},
 		{TokenMul, "*"}, {TokenSpace, " "},
 		{TokenId, "eaw"}, {TokenColon, ":"},
 		{TokenId, "F"}}},
-	{"@fold(cat:Lu)", []Token{
+	{"@fold(cat:Lu)", []lexToken{
 		{TokenAt, "@"}, {TokenId, "fold"},
 		{TokenLParen, "("}, {TokenId, "cat"},
 		{TokenColon, ":"}, {TokenId, "Lu"},
 		{TokenRParen, ")"}}},
+	{"let w=eaw:W;$w", []lexToken{
+		{TokenId, "let"}, {TokenSpace, " "},
+		{TokenId, "w"}, {TokenAssign, "="},
+		{TokenId, "eaw"}, {TokenColon, ":"},
+		{TokenId, "W"}, {TokenSemicolon, ";"},
+		{TokenVar, "$w"}}},
+	{"include \"a\\\"b.uset\" # comment\n12", []lexToken{
+		{TokenId, "include"}, {TokenSpace, " "},
+		{TokenString, `"a\"b.uset"`}, {TokenSpace, " "},
+		{TokenComment, "# comment"}, {TokenSpace, "\n"},
+		{TokenRune, "12"}}},
+	{"age:<=15.0,1.1..2", []lexToken{
+		{TokenId, "age"}, {TokenColon, ":"},
+		{TokenLessEq, "<="}, {TokenVersion, "15.0"},
+		{TokenComma, ","}, {TokenVersion, "1.1"},
+		{TokenRange, ".."}, {TokenRune, "2"}}},
+	{`name:/A\/B/`, []lexToken{
+		{TokenId, "name"}, {TokenColon, ":"},
+		{TokenRegex, `/A\/B/`}}},
+	{"nv:1/2,5", []lexToken{
+		{TokenId, "nv"}, {TokenColon, ":"},
+		{TokenRational, "1/2"}, {TokenComma, ","}, {TokenRune, "5"}}},
 }
 
 func TestLexer(t *testing.T) {
 	for _, testCase := range lexerTestCases {
 		actual, err := Tokenize([]byte(testCase.src))
 		assert.Nil(t, err)
-		assert.Equal(t, testCase.tokens, actual, testCase.src)
+		tokens := make([]lexToken, 0, len(actual))
+		for _, token := range actual {
+			tokens = append(tokens, lexToken{token.kind, token.text})
+		}
+		assert.Equal(t, testCase.tokens, tokens, testCase.src)
 	}
 }
 
+func TestLexerPos(t *testing.T) {
+	tokens, err := Tokenize([]byte("let x = \"α\";\n  $x"))
+	assert.Nil(t, err)
+	assert.Equal(t, 10, len(tokens))
+	assert.Equal(t, Pos{Offset: 0, Line: 1, Col: 1}, tokens[0].pos)
+	assert.Equal(t, Pos{Offset: 8, Line: 1, Col: 9}, tokens[6].pos)   // "α"
+	assert.Equal(t, Pos{Offset: 12, Line: 1, Col: 12}, tokens[7].pos) // ;
+	assert.Equal(t, Pos{Offset: 16, Line: 2, Col: 3}, tokens[9].pos)  // $x
+
+	_, err = Tokenize([]byte("12 +\n ?"))
+	var syntaxError *SyntaxError
+	assert.ErrorAs(t, err, &syntaxError)
+	assert.Equal(t, Pos{Offset: 6, Line: 2, Col: 2}, syntaxError.Pos)
+}
+
 func TestParserPrimary(t *testing.T) {
 	aliasMaps := NewAliasMapRecord()
 
@@ -183,3 +233,511 @@ func TestParserCaseFold(t *testing.T) {
 	_, err = NewParser(aliasMaps, nil).Run([]byte("@unknown(41)"))
 	assert.NotNil(t, err)
 }
+
+func TestParserCaseClosure(t *testing.T) {
+	aliasMaps := NewAliasMapRecord()
+
+	node, err := NewParser(aliasMaps, nil).Run([]byte("@closure(4B)"))
+	assert.Nil(t, err)
+	assert.False(t, node.(*CaseClosureNode).turkic)
+	node, err = NewParser(aliasMaps, nil).Run([]byte("@closure(49, turkic)"))
+	assert.Nil(t, err)
+	assert.True(t, node.(*CaseClosureNode).turkic)
+	_, err = NewParser(aliasMaps, nil).Run([]byte("@closure(49, full)"))
+	assert.Equal(t, "[syntax error] unknown case closure mode: full, must be `turkic`", firstLine(err))
+
+	// eval
+	foldMap := NewCaseFoldMap([][2]rune{{0x4B, 0x6B}, {0x212A, 0x6B}, {0x49, 0x69}})
+	foldMap.AddTurkic(0x49, 0x131)
+	ctx := &EvalContext{CaseFoldingMap: foldMap}
+	uniSet := (&CaseClosureNode{&RangeNode{set.RuneRange{First: 0x6B, Last: 0x6B}}, false}).Eval(ctx)
+	assert.Equal(t, "{0x004b..0x004b,0x006b..0x006b,0x212a..0x212a}", uniSet.String())
+	uniSet = (&CaseClosureNode{&RangeNode{set.RuneRange{First: 0x131, Last: 0x131}}, true}).Eval(ctx)
+	assert.Equal(t, "{0x0049..0x0049,0x0131..0x0131}", uniSet.String())
+}
+
+func TestParserCaseMapping(t *testing.T) {
+	aliasMaps := NewAliasMapRecord()
+
+	node, err := NewParser(aliasMaps, nil).Run([]byte("@upper(61..7A) + @lower(41) + @title(01C6)"))
+	assert.Nil(t, err)
+	assert.Equal(t, CaseMappingUpper, node.(*UnionNode).left.(*UnionNode).left.(*CaseMappingNode).kind)
+	assert.Equal(t, CaseMappingLower, node.(*UnionNode).left.(*UnionNode).right.(*CaseMappingNode).kind)
+	assert.Equal(t, CaseMappingTitle, node.(*UnionNode).right.(*CaseMappingNode).kind)
+
+	node, err = NewParser(aliasMaps, nil).Run([]byte("@fold(41..5A, full)"))
+	assert.Nil(t, err)
+	assert.Equal(t, CaseMappingFullFold, node.(*CaseMappingNode).kind)
+	node, err = NewParser(aliasMaps, nil).Run([]byte("@fold(41..5A, simple)"))
+	assert.Nil(t, err)
+	assert.IsType(t, &CaseFoldNode{}, node)
+
+	_, err = NewParser(aliasMaps, nil).Run([]byte("@fold(41, turkic)"))
+	assert.Equal(t, "[syntax error] unknown case folding mode: turkic, must be `simple` or `full`", firstLine(err))
+	_, err = NewParser(aliasMaps, nil).Run([]byte("@upper(41, full)"))
+	assert.NotNil(t, err)
+
+	// eval
+	foldMap := NewCaseFoldMap([][2]rune{{0x41, 0x61}, {0x1E9E, 0xDF}})
+	foldMap.AddFull(0xDF, []rune{0x73, 0x73})
+	caseMapping := NewCaseMapping()
+	caseMapping.SetUpper(0xDF, []rune{0x53, 0x53})
+	caseMapping.SetUpper(0x61, []rune{0x41})
+	ctx := &EvalContext{CaseFoldingMap: foldMap, CaseMapping: caseMapping}
+	uniSet := (&CaseMappingNode{&RangeNode{set.RuneRange{First: 0x61, Last: 0x62}}, CaseMappingUpper}).Eval(ctx)
+	assert.Equal(t, "{0x0041..0x0041,0x0062..0x0062}", uniSet.String())
+	uniSet = (&CaseMappingNode{&RangeNode{set.RuneRange{First: 0xDF, Last: 0xDF}}, CaseMappingUpper}).Eval(ctx)
+	assert.Equal(t, "{0x0053..0x0053}", uniSet.String())
+	uniSet = (&CaseMappingNode{&RangeNode{set.RuneRange{First: 0xDF, Last: 0xDF}}, CaseMappingFullFold}).Eval(ctx)
+	assert.Equal(t, "{0x0073..0x0073}", uniSet.String())
+}
+
+func TestParserLet(t *testing.T) {
+	aliasMaps := NewAliasMapRecord()
+
+	node, err := NewParser(aliasMaps, nil).Run([]byte("let word = cat:L + 5F; $word - 41"))
+	assert.Nil(t, err)
+	assert.IsType(t, &DiffNode{}, node)
+	assert.IsType(t, &VarNode{}, node.(*DiffNode).left)
+	binding := node.(*DiffNode).left.(*VarNode).binding
+	assert.NotNil(t, binding)
+	assert.Equal(t, "word", binding.name)
+	assert.IsType(t, &UnionNode{}, binding.node)
+
+	// forward reference
+	node, err = NewParser(aliasMaps, nil).Run([]byte("let a = $b + 41; let b = 42..45; $a"))
+	assert.Nil(t, err)
+	assert.IsType(t, &VarNode{}, node)
+	assert.IsType(t, &UnionNode{}, node.(*VarNode).binding.node)
+	assert.Equal(t, "b", node.(*VarNode).binding.node.(*UnionNode).left.(*VarNode).binding.name)
+
+	// undefined
+	_, err = NewParser(aliasMaps, nil).Run([]byte("let a = 41; $b"))
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "undefined variable: $b")
+
+	// cyclic
+	_, err = NewParser(aliasMaps, nil).Run([]byte("let a = $b; let b = 41 + $c; let c = $a; $a"))
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "cyclic definition: $a -> $b -> $c -> $a")
+
+	_, err = NewParser(aliasMaps, nil).Run([]byte("let a = $a; 41"))
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "cyclic definition: $a -> $a")
+
+	// duplicated
+	_, err = NewParser(aliasMaps, nil).Run([]byte("let a = 41; let a = 42; $a"))
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "already defined: $a")
+
+	// missing final expression
+	_, err = NewParser(aliasMaps, nil).Run([]byte("let a = 41;"))
+	assert.NotNil(t, err)
+}
+
+type countNode struct {
+	count int
+}
+
+func (c *countNode) Eval(*EvalContext) set.UniSet {
+	c.count++
+	return set.NewUniSet(0x41)
+}
+
+func TestEvalLet(t *testing.T) {
+	counter := &countNode{}
+	binding := &Binding{name: "a", node: counter}
+	node := &UnionNode{&VarNode{name: "a", binding: binding},
+		&DiffNode{&VarNode{name: "a", binding: binding}, &RangeNode{set.RuneRange{First: 0x41, Last: 0x41}}}}
+
+	ctx := &EvalContext{}
+	uniSet := node.Eval(ctx)
+	assert.Equal(t, "{0x0041..0x0041}", uniSet.String())
+	assert.Equal(t, 1, counter.count) // evaluate only once
+
+	uniSet = node.Eval(ctx)
+	assert.Equal(t, "{0x0041..0x0041}", uniSet.String())
+	assert.Equal(t, 1, counter.count)
+
+	uniSet = node.Eval(&EvalContext{}) // re-evaluate in another context
+	assert.Equal(t, "{0x0041..0x0041}", uniSet.String())
+	assert.Equal(t, 2, counter.count)
+}
+
+func firstLine(err error) string {
+	return strings.SplitN(err.Error(), "\n", 2)[0]
+}
+
+func writeFile(t *testing.T, dir string, name string, content string) string {
+	path := filepath.Join(dir, name)
+	assert.Nil(t, os.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+func TestParserFile(t *testing.T) {
+	aliasMaps := NewAliasMapRecord()
+	dir := t.TempDir()
+	writeFile(t, dir, "common.uset", "# shared definitions\nlet a = 0..7F;\n")
+	writeFile(t, dir, "common2.uset", "include \"common.uset\" let b = 80..U+FF;")
+	path := writeFile(t, dir, "rules.uset", `# rules
+include "common.uset"
+include "./common2.uset"  # already included common.uset
+
+$a   # ascii
+  + $b
+`)
+	node, err := NewParser(aliasMaps, nil).RunFile(path)
+	assert.Nil(t, err)
+	uniSet := node.Eval(&EvalContext{})
+	assert.Equal(t, "{0x0000..0x00ff}", uniSet.String())
+
+	// error location
+	path = writeFile(t, dir, "error1.uset", "let a = 12;\n\n$a + $c\n")
+	_, err = NewParser(aliasMaps, nil).RunFile(path)
+	assert.Equal(t, path+":3:6: [syntax error] undefined variable: $c", firstLine(err))
+
+	writeFile(t, dir, "error2.uset", "let a = 12\nlet b = 34;")
+	path = writeFile(t, dir, "error3.uset", "include \"error2.uset\"\n$a")
+	_, err = NewParser(aliasMaps, nil).RunFile(path)
+	assert.Equal(t, filepath.Join(dir, "error2.uset")+
+		":2:1: [syntax error] unexpected identifier: let, expect: '..', '*', '+', '-' or ';'", firstLine(err))
+
+	writeFile(t, dir, "error4.uset", "let a = 12;\n$a")
+	path = writeFile(t, dir, "error5.uset", "include \"error4.uset\"\n$a")
+	_, err = NewParser(aliasMaps, nil).RunFile(path)
+	assert.Equal(t, filepath.Join(dir, "error4.uset")+
+		":2:1: [syntax error] unexpected variable, included file must only have `let` or `include`", firstLine(err))
+
+	writeFile(t, dir, "cyclic1.uset", "include \"cyclic2.uset\"")
+	writeFile(t, dir, "cyclic2.uset", "include \"cyclic1.uset\"")
+	path = writeFile(t, dir, "error6.uset", "include \"cyclic1.uset\"\n12")
+	_, err = NewParser(aliasMaps, nil).RunFile(path)
+	assert.Equal(t, filepath.Join(dir, "cyclic2.uset")+
+		":1:9: [syntax error] cyclic include: "+filepath.Join(dir, "cyclic1.uset"), firstLine(err))
+
+	path = writeFile(t, dir, "error7.uset", "include \"not_found.uset\"\n12")
+	_, err = NewParser(aliasMaps, nil).RunFile(path)
+	assert.Contains(t, firstLine(err), path+":1:9: [syntax error] cannot include")
+
+	path = writeFile(t, dir, "error8.uset", "12 +\n  ?")
+	_, err = NewParser(aliasMaps, nil).RunFile(path)
+	assert.Equal(t, path+":2:3: [syntax error] invalid token: '?'", firstLine(err))
+
+	path = writeFile(t, dir, "error9.uset", "12 +\n")
+	_, err = NewParser(aliasMaps, nil).RunFile(path)
+	assert.Equal(t, path+":2:1: [syntax error] unexpected end of input, "+
+		"expect: '!', '@', identifier, codePoint, variable or '('", firstLine(err))
+}
+
+func TestSyntaxError(t *testing.T) {
+	aliasMaps := NewAliasMapRecord()
+	testCases := []struct {
+		src string
+		msg string
+	}{
+		{"cat::Lu", "[syntax error] unexpected ':', expect: identifier, codePoint, version, rational or string\ncat::Lu\n    ^"},
+		{"12 + (cat:Lu", "[syntax error] unexpected end of input, expect: ',', '*', '+', '-' or ')'\n12 + (cat:Lu\n            ^"},
+		{"cat:Lu eaw:W", "[syntax error] unexpected identifier: eaw, expect: ',', '*', '+', '-' or end of input\n" +
+			"cat:Lu eaw:W\n       ^~~"},
+		{"12 +\n\t  cat:Lx", "[syntax error] unknown general category: Lx, did you mean `L`, `LC` or `Ll`?\n\t  cat:Lx\n\t      ^~"},
+		{"@unknown(41)", "[syntax error] unknown function: unknown\n@unknown(41)\n ^~~~~~~"},
+		{"12 ? 34", "[syntax error] invalid token: '?'\n12 ? 34\n   ^"},
+		{"let a = 12; $a + $b", "[syntax error] undefined variable: $b\nlet a = 12; $a + $b\n                 ^~"},
+	}
+	for _, testCase := range testCases {
+		_, err := NewParser(aliasMaps, nil).Run([]byte(testCase.src))
+		assert.Equal(t, testCase.msg, err.Error(), testCase.src)
+	}
+
+	_, err := NewParser(aliasMaps, nil).Run([]byte("12 + * 34"))
+	var syntaxError *SyntaxError
+	assert.ErrorAs(t, err, &syntaxError)
+	assert.Equal(t, Pos{Offset: 5, Line: 1, Col: 6}, syntaxError.Pos)
+	assert.Equal(t, []TokenKind{TokenNegate, TokenAt, TokenId, TokenRune, TokenVar, TokenLParen}, syntaxError.Expected)
+}
+
+func TestParserUnicodeData(t *testing.T) {
+	aliasMaps := NewAliasMapRecord()
+	aliasMaps.CombiningClass().AddAll("230", []string{"A", "Above"})
+	aliasMaps.DecompositionType().AddAll("Com", []string{"Compat", "com"})
+	defRecord := newTestDefRecord(aliasMaps)
+
+	node, err := NewParser(aliasMaps, defRecord).Run([]byte("bc:AL,R"))
+	assert.Nil(t, err)
+	assert.Equal(t, []BidiClass{1, 2}, node.(*PropertyNode[BidiClass]).properties)
+
+	node, err = NewParser(aliasMaps, defRecord).Run([]byte("ccc:230,1 + ccc:Above"))
+	assert.Nil(t, err)
+	assert.Equal(t, []CombiningClass{1, 2}, node.(*UnionNode).left.(*PropertyNode[CombiningClass]).properties)
+	assert.Equal(t, []CombiningClass{2}, node.(*UnionNode).right.(*PropertyNode[CombiningClass]).properties)
+
+	node, err = NewParser(aliasMaps, defRecord).Run([]byte("dt:compat"))
+	assert.Nil(t, err)
+	assert.Equal(t, []DecompositionType{1}, node.(*PropertyNode[DecompositionType]).properties)
+
+	_, err = NewParser(aliasMaps, defRecord).Run([]byte("ccc:231"))
+	assert.Equal(t, "[syntax error] unknown property: 231, did you mean `230`?", firstLine(err))
+}
+
+func TestParserAge(t *testing.T) {
+	aliasMaps := NewAliasMapRecord()
+	aliasMaps.Age().AddAll("13.0", []string{"V13_0"})
+	defRecord := newTestDefRecord(aliasMaps)
+	ages := func(node Node) []Age {
+		return node.(*PropertyNode[Age]).properties
+	}
+
+	node, err := NewParser(aliasMaps, defRecord).Run([]byte("age:15.0,1.1"))
+	assert.Nil(t, err)
+	assert.Equal(t, []Age{0, 4}, ages(node))
+
+	node, err = NewParser(aliasMaps, defRecord).Run([]byte("age:V13_0"))
+	assert.Nil(t, err)
+	assert.Equal(t, []Age{2}, ages(node))
+
+	node, err = NewParser(aliasMaps, defRecord).Run([]byte("age:<=13.0"))
+	assert.Nil(t, err)
+	assert.Equal(t, []Age{0, 1, 2}, ages(node))
+
+	node, err = NewParser(aliasMaps, defRecord).Run([]byte("age:<=V13_0"))
+	assert.Nil(t, err)
+	assert.Equal(t, []Age{0, 1, 2}, ages(node))
+
+	node, err = NewParser(aliasMaps, defRecord).Run([]byte("@since(14.1)"))
+	assert.Nil(t, err)
+	assert.Equal(t, []Age{0, 1, 2, 3}, ages(node))
+
+	node, err = NewParser(aliasMaps, defRecord).Run([]byte("age:NA"))
+	assert.Nil(t, err)
+	assert.Equal(t, []Age{5}, ages(node))
+
+	_, err = NewParser(aliasMaps, defRecord).Run([]byte("age:15.1"))
+	assert.Equal(t, "[syntax error] unknown property: 15.1, did you mean `1.1` or `15.0`?", firstLine(err))
+	_, err = NewParser(aliasMaps, defRecord).Run([]byte("@since(NA)"))
+	assert.Equal(t, "[syntax error] invalid version: NA", firstLine(err))
+}
+
+func TestParserBlock(t *testing.T) {
+	aliasMaps := NewAliasMapRecord()
+	aliasMaps.Block().AddAll("ASCII", []string{"Basic_Latin"})
+	aliasMaps.Block().AddAll("Box_Drawing", []string{"Box_Drawing"})
+	aliasMaps.Block().AddAll("NB", []string{"No_Block"})
+	defRecord := newTestDefRecord(aliasMaps)
+
+	for _, src := range []string{"blk:Box_Drawing", `blk:"box drawing"`, "blk:BOXDRAWING"} {
+		node, err := NewParser(aliasMaps, defRecord).Run([]byte(src))
+		assert.Nil(t, err, src)
+		assert.Equal(t, []Block{1}, node.(*PropertyNode[Block]).properties, src)
+	}
+	node, err := NewParser(aliasMaps, defRecord).Run([]byte(`blk:"Basic Latin",No_Block`))
+	assert.Nil(t, err)
+	assert.Equal(t, []Block{0, 2}, node.(*PropertyNode[Block]).properties)
+
+	_, err = NewParser(aliasMaps, defRecord).SetStrict(true).Run([]byte(`blk:"box drawing"`))
+	assert.Equal(t, "[syntax error] unknown property: box drawing, did you mean `Box_Drawing`?", firstLine(err))
+}
+
+func TestParserJoining(t *testing.T) {
+	aliasMaps := NewAliasMapRecord()
+	aliasMaps.JoiningType().AddAll("D", []string{"Dual_Joining"})
+	aliasMaps.JoiningType().AddAll("U", []string{"Non_Joining"})
+	aliasMaps.JoiningGroup().AddAll("African_Feh", []string{"African_Feh"})
+	defRecord := newTestDefRecord(aliasMaps)
+
+	node, err := NewParser(aliasMaps, defRecord).Run([]byte("jt:D,R,Non_Joining"))
+	assert.Nil(t, err)
+	assert.Equal(t, []JoiningType{0, 1, 3}, node.(*PropertyNode[JoiningType]).properties)
+	node, err = NewParser(aliasMaps, defRecord).Run([]byte(`jg:"african feh",beh`))
+	assert.Nil(t, err)
+	assert.Equal(t, []JoiningGroup{0, 1}, node.(*PropertyNode[JoiningGroup]).properties)
+
+	_, err = NewParser(aliasMaps, defRecord).Run([]byte("jg:D"))
+	assert.Equal(t, "[syntax error] unknown property: D, did you mean `jt:D`?", firstLine(err))
+}
+
+func TestParserNumeric(t *testing.T) {
+	aliasMaps := NewAliasMapRecord()
+	aliasMaps.NumericType().AddAll("De", []string{"Decimal"})
+	defRecord := newTestDefRecord(aliasMaps)
+
+	node, err := NewParser(aliasMaps, defRecord).Run([]byte("nt:De,Di,Nu"))
+	assert.Nil(t, err)
+	assert.Equal(t, []NumericType{0, 1, 2}, node.(*PropertyNode[NumericType]).properties)
+	node, err = NewParser(aliasMaps, defRecord).Run([]byte("nt:Decimal"))
+	assert.Nil(t, err)
+	assert.Equal(t, []NumericType{0}, node.(*PropertyNode[NumericType]).properties)
+
+	node, err = NewParser(aliasMaps, defRecord).Run([]byte(`nv:5,1/2,"-1/2",NaN`))
+	assert.Nil(t, err)
+	assert.Equal(t, []NumericValue{0, 2, 3, 4}, node.(*PropertyNode[NumericValue]).properties)
+
+	// negative value without quotes
+	node, err = NewParser(aliasMaps, defRecord).Run([]byte("nv:-1/2,0"))
+	assert.Nil(t, err)
+	assert.Equal(t, []NumericValue{0, 1}, node.(*PropertyNode[NumericValue]).properties)
+	node, err = NewParser(aliasMaps, defRecord).Run([]byte("nv:5 - nv:-1/2"))
+	assert.Nil(t, err)
+	assert.Equal(t, []NumericValue{0}, node.(*DiffNode).right.(*PropertyNode[NumericValue]).properties)
+	_, err = NewParser(aliasMaps, defRecord).Run([]byte("nv:- 1/2"))
+	assert.NotNil(t, err)
+
+	_, err = NewParser(aliasMaps, defRecord).Run([]byte("nv:1/3"))
+	assert.Equal(t, "[syntax error] unknown property: 1/3, did you mean `-1/2` or `1/2`?", firstLine(err))
+
+	// numeric values are exactly matched (not match `1/2` even if `-1/2` is absent)
+	defRecord.NumericValueDef = NewExactPropertyDef[NumericValue]([]string{"0", "1/2", "5", "NaN"})
+	_, err = NewParser(aliasMaps, defRecord).Run([]byte("nv:-1/2"))
+	assert.Equal(t, "[syntax error] unknown property: -1/2, did you mean `1/2`?", firstLine(err))
+	_, err = NewParser(aliasMaps, defRecord).Run([]byte("nv:nan"))
+	assert.NotNil(t, err)
+}
+
+func TestParserIdentifier(t *testing.T) {
+	aliasMaps := NewAliasMapRecord()
+	defRecord := newTestDefRecord(aliasMaps)
+
+	node, err := NewParser(aliasMaps, defRecord).Run([]byte("idst:Allowed * idty:Recommended,Inclusion"))
+	assert.Nil(t, err)
+	assert.Equal(t, []IdentifierStatus{0}, node.(*IntersectNode).left.(*PropertyNode[IdentifierStatus]).properties)
+	assert.Equal(t, []IdentifierType{0, 1}, node.(*IntersectNode).right.(*PropertyNode[IdentifierType]).properties)
+
+	_, err = NewParser(aliasMaps, defRecord).Run([]byte("idty:Allowed"))
+	assert.Equal(t, "[syntax error] unknown property: Allowed, did you mean `idst:Allowed`?", firstLine(err))
+}
+
+func TestParserLineBreak(t *testing.T) {
+	aliasMaps := NewAliasMapRecord()
+	aliasMaps.LineBreak().AddAll("BA", []string{"Break_After"})
+	aliasMaps.LineBreak().AddAll("XX", []string{"Unknown"})
+	defRecord := newTestDefRecord(aliasMaps)
+	ctx := &EvalContext{
+		DefRecord: *defRecord,
+		LineBreakMap: UniSetMap[LineBreak]{
+			0: new(set.NewUniSet(0x20)),
+			1: new(set.NewUniSet(0x2D)),
+			2: new(set.NewUniSet(0x41)),
+		},
+	}
+
+	node, err := NewParser(aliasMaps, defRecord).Run([]byte("lb:break_after,HY"))
+	assert.Nil(t, err)
+	assert.Equal(t, []LineBreak{0, 1}, node.(*PropertyNode[LineBreak]).properties)
+	uniSet := node.Eval(ctx)
+	assert.Equal(t, "{0x0020..0x0020,0x002d..0x002d}", uniSet.String())
+
+	node, err = NewParser(aliasMaps, defRecord).Run([]byte("lb:Unknown"))
+	assert.Nil(t, err)
+	uniSet = node.Eval(ctx)
+	assert.Equal(t, "{0x0041..0x0041}", uniSet.String())
+}
+
+func TestParserIndic(t *testing.T) {
+	aliasMaps := NewAliasMapRecord()
+	defRecord := newTestDefRecord(aliasMaps)
+	ctx := &EvalContext{
+		DefRecord: *defRecord,
+		IndicSyllabicCategoryMap: UniSetMap[IndicSyllabicCategory]{
+			0: new(set.NewUniSet(0x0901, 0x0902)),
+			1: new(set.NewUniSet(0x093E)),
+		},
+		IndicPositionalCategoryMap: UniSetMap[IndicPositionalCategory]{
+			0: new(set.NewUniSet(0x0901)),
+			1: new(set.NewUniSet(0x0941)),
+		},
+	}
+
+	node, err := NewParser(aliasMaps, defRecord).Run([]byte("insc:Bindu,vowel_dependent"))
+	assert.Nil(t, err)
+	assert.Equal(t, []IndicSyllabicCategory{0, 1}, node.(*PropertyNode[IndicSyllabicCategory]).properties)
+	uniSet := node.Eval(ctx)
+	assert.Equal(t, "{0x0901..0x0902,0x093e..0x093e}", uniSet.String())
+
+	node, err = NewParser(aliasMaps, defRecord).Run([]byte("inpc:Bottom + insc:Bindu * inpc:Top"))
+	assert.Nil(t, err)
+	uniSet = node.Eval(ctx)
+	assert.Equal(t, "{0x0901..0x0901,0x0941..0x0941}", uniSet.String())
+
+	_, err = NewParser(aliasMaps, defRecord).Run([]byte("inpc:Left"))
+	assert.NotNil(t, err)
+}
+
+func TestParserVerticalOrientationAndHangulSyllableType(t *testing.T) {
+	aliasMaps := NewAliasMapRecord()
+	aliasMaps.VerticalOrientation().AddAll("Tu", []string{"Transformed_Upright"})
+	aliasMaps.HangulSyllableType().AddAll("LVT", []string{"LVT_Syllable"})
+	defRecord := newTestDefRecord(aliasMaps)
+
+	node, err := NewParser(aliasMaps, defRecord).Run([]byte("vo:U,transformed_upright"))
+	assert.Nil(t, err)
+	assert.Equal(t, []VerticalOrientation{0, 1}, node.(*PropertyNode[VerticalOrientation]).properties)
+
+	node, err = NewParser(aliasMaps, defRecord).Run([]byte("hst:LV,LVT_Syllable,NA"))
+	assert.Nil(t, err)
+	assert.Equal(t, []HangulSyllableType{3, 4, 5}, node.(*PropertyNode[HangulSyllableType]).properties)
+}
+
+func TestParserBidiMirroring(t *testing.T) {
+	aliasMaps := NewAliasMapRecord()
+	aliasMaps.BidiPairedBracketType().AddAll("o", []string{"Open"})
+	defRecord := newTestDefRecord(aliasMaps)
+	ctx := &EvalContext{
+		DefRecord:        *defRecord,
+		BidiMirroringMap: RuneMap{'(': ')', ')': '(', '<': '>', '>': '<'},
+		BidiPairedBracketTypeMap: UniSetMap[BidiPairedBracketType]{
+			0: new(set.NewUniSet('(')),
+			1: new(set.NewUniSet(')')),
+		},
+	}
+
+	node, err := NewParser(aliasMaps, defRecord).Run([]byte("bpt:open,c"))
+	assert.Nil(t, err)
+	uniSet := node.Eval(ctx)
+	assert.Equal(t, "{0x0028..0x0029}", uniSet.String())
+
+	node, err = NewParser(aliasMaps, defRecord).Run([]byte("@mirror(bpt:o + U+3C + U+41)"))
+	assert.Nil(t, err)
+	uniSet = node.Eval(ctx)
+	assert.Equal(t, "{0x0029..0x0029,0x003e..0x003e}", uniSet.String())
+}
+
+func TestParserName(t *testing.T) {
+	names := NewCharNames()
+	names.Add(0x2190, "LEFTWARDS ARROW")
+	names.Add(0x2192, "RIGHTWARDS ARROW")
+	names.Add(0x2013, "EN DASH")
+	names.Add(0x2E3A, "TWO-EM DASH")
+	names.Add(0x0009, "<control>")
+	names.AddAlias(0x0009, "CHARACTER TABULATION")
+	names.AddRange(set.RuneRange{First: 0xAC00, Last: 0xD7A3}, "Hangul Syllable")
+	names.AddRange(set.RuneRange{First: 0x4E00, Last: 0x9FFF}, "CJK Ideograph")
+	ctx := &EvalContext{CharNames: names}
+
+	testCases := []struct {
+		src      string
+		expected string
+	}{
+		{"name:/ARROW/", "{0x2190..0x2190,0x2192..0x2192}"},
+		{"name:/^RIGHT/", "{0x2192..0x2192}"},
+		{`@name("*DASH*")`, "{0x2013..0x2013,0x2e3a..0x2e3a}"},
+		{`@name("*dash")`, "{0x2013..0x2013,0x2e3a..0x2e3a}"},
+		{`name:"EN?DASH"`, "{0x2013..0x2013}"},
+		{"name:/TABULATION/", "{0x0009..0x0009}"},
+		{"name:/control/", "{}"}, // pseudo name is not matched
+		{`@name("*CONTROL*")`, "{}"},
+		{"name:/^HANGUL SYLLABLE GA$/ + name:/-4E0[01]$/", "{0x4e00..0x4e01,0xac00..0xac00}"},
+		{`@name(/\//)`, "{}"},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.src, func(t *testing.T) {
+			node, err := NewParser(NewAliasMapRecord(), nil).Run([]byte(testCase.src))
+			assert.Nil(t, err)
+			uniSet := node.Eval(ctx)
+			assert.Equal(t, testCase.expected, uniSet.String())
+		})
+	}
+
+	_, err := NewParser(NewAliasMapRecord(), nil).Run([]byte("name:/(/"))
+	assert.Equal(t, "[syntax error] invalid name pattern: error parsing regexp: missing closing ): `(`", firstLine(err))
+	_, err = NewParser(NewAliasMapRecord(), nil).Run([]byte("name:ARROW"))
+	assert.Equal(t, "[syntax error] unexpected identifier: ARROW, expect: regex or string", firstLine(err))
+}
@@ -2,7 +2,9 @@ package op
 
 import (
 	"fmt"
+	"maps"
 	"slices"
+	"strconv"
 	"strings"
 )
 
@@ -83,14 +85,18 @@ func EachGeneralCategoryAll(yield func(GeneralCategory) bool) {
 }
 
 var abbrToGeneralCategory map[string]GeneralCategory
+var looseToGeneralCategory map[string]GeneralCategory
 
 func init() {
 	abbrToGeneralCategory = make(map[string]GeneralCategory)
+	looseToGeneralCategory = make(map[string]GeneralCategory)
 	for cat := range EachGeneralCategoryAll {
 		abbrToGeneralCategory[cat.String()] = cat
+		looseToGeneralCategory[looseMatchKey(cat.String())] = cat
 	}
 }
 
+// ParseGeneralCategory parse general category abbreviation or long name (with loose matching)
 func ParseGeneralCategory(s string, aliasMap *AliasMap) (GeneralCategory, error) {
 	if c, ok := abbrToGeneralCategory[s]; ok {
 		return c, nil
@@ -102,7 +108,16 @@ func ParseGeneralCategory(s string, aliasMap *AliasMap) (GeneralCategory, error)
 			}
 		}
 	}
-	return GeneralCategory(0), fmt.Errorf("unknown general category: %s", s)
+	if c, ok := looseToGeneralCategory[looseMatchKey(s)]; ok {
+		return c, nil
+	}
+	if aliasMap != nil {
+		if c, ok := abbrToGeneralCategory[aliasMap.LookupAbbrLoose(s)]; ok {
+			return c, nil
+		}
+	}
+	return GeneralCategory(0), newUnknownPropertyValueError("general category", s,
+		aliasCandidates(slices.Collect(maps.Keys(abbrToGeneralCategory)), aliasMap))
 }
 
 var combinedGeneralCategory = map[GeneralCategory][]GeneralCategory{
@@ -120,11 +135,14 @@ func (c GeneralCategory) Combinations() []GeneralCategory {
 	return combinedGeneralCategory[c]
 }
 
-func (c GeneralCategory) Format(aliasMap *AliasMap) string {
+// Names returns abbreviation and long names
+func (c GeneralCategory) Names(aliasMap *AliasMap) []string {
 	abbr := c.String()
-	tmp := []string{abbr}
-	tmp = append(tmp, aliasMap.Lookup(abbr)...)
-	return strings.Join(tmp, ", ")
+	return append([]string{abbr}, aliasMap.Lookup(abbr)...)
+}
+
+func (c GeneralCategory) Format(aliasMap *AliasMap) string {
+	return strings.Join(c.Names(aliasMap), ", ")
 }
 
 //go:generate go run -mod=mod golang.org/x/tools/cmd/stringer -type EastAsianWidth -trimprefix EAW_
@@ -164,14 +182,18 @@ func EachEastAsianWidth(yield func(EastAsianWidth) bool) {
 }
 
 var abbrToEastAsianWidth map[string]EastAsianWidth
+var looseToEastAsianWidth map[string]EastAsianWidth
 
 func init() {
 	abbrToEastAsianWidth = make(map[string]EastAsianWidth)
+	looseToEastAsianWidth = make(map[string]EastAsianWidth)
 	for eaw := range EachEastAsianWidth {
 		abbrToEastAsianWidth[eaw.String()] = eaw
+		looseToEastAsianWidth[looseMatchKey(eaw.String())] = eaw
 	}
 }
 
+// ParseEastAsianWidth parse east asian width abbreviation or long name (with loose matching)
 func ParseEastAsianWidth(s string, aliasMap *AliasMap) (EastAsianWidth, error) {
 	if c, ok := abbrToEastAsianWidth[s]; ok {
 		return c, nil
@@ -183,14 +205,26 @@ func ParseEastAsianWidth(s string, aliasMap *AliasMap) (EastAsianWidth, error) {
 			}
 		}
 	}
-	return EastAsianWidth(0), fmt.Errorf("unknown east asian width: %s", s)
+	if c, ok := looseToEastAsianWidth[looseMatchKey(s)]; ok {
+		return c, nil
+	}
+	if aliasMap != nil {
+		if c, ok := abbrToEastAsianWidth[aliasMap.LookupAbbrLoose(s)]; ok {
+			return c, nil
+		}
+	}
+	return EastAsianWidth(0), newUnknownPropertyValueError("east asian width", s,
+		aliasCandidates(slices.Collect(maps.Keys(abbrToEastAsianWidth)), aliasMap))
 }
 
-func (e EastAsianWidth) Format(aliasMap *AliasMap) string {
+// Names returns abbreviation and long names
+func (e EastAsianWidth) Names(aliasMap *AliasMap) []string {
 	abbr := e.String()
-	tmp := []string{abbr}
-	tmp = append(tmp, aliasMap.Lookup(abbr)...)
-	return strings.Join(tmp, ", ")
+	return append([]string{abbr}, aliasMap.Lookup(abbr)...)
+}
+
+func (e EastAsianWidth) Format(aliasMap *AliasMap) string {
+	return strings.Join(e.Names(aliasMap), ", ")
 }
 
 const ScriptPrefix = "sc"
@@ -202,22 +236,32 @@ func IsScriptPrefix(s string) bool {
 type Script int
 
 type ScriptDef struct {
-	scriptToAbbr []string
-	abbrToScript map[string]Script
-	unknown      Script
+	scriptToAbbr  []string
+	abbrToScript  map[string]Script
+	looseToScript map[string]Script
+	unknown       Script
 }
 
+// ScriptUnknown Script value of code points not listed in Scripts.txt
+const ScriptUnknown = "Unknown"
+
 func NewScriptDef(longs []string, aliasMap *AliasMap) *ScriptDef {
-	longs = append(longs, "Unknown")
+	unknown := slices.Index(longs, ScriptUnknown)
+	if unknown < 0 {
+		unknown = len(longs)
+		longs = append(longs, ScriptUnknown)
+	}
 	s := &ScriptDef{
-		scriptToAbbr: make([]string, len(longs)),
-		abbrToScript: make(map[string]Script),
-		unknown:      Script(len(longs) - 1),
+		scriptToAbbr:  make([]string, len(longs)),
+		abbrToScript:  make(map[string]Script),
+		looseToScript: make(map[string]Script),
+		unknown:       Script(unknown),
 	}
 	for i, long := range longs {
 		abbr := aliasMap.LookupAbbr(long)
 		s.scriptToAbbr[i] = abbr
 		s.abbrToScript[abbr] = Script(i)
+		s.looseToScript[looseMatchKey(abbr)] = Script(i)
 	}
 	return s
 }
@@ -234,6 +278,7 @@ func (d *ScriptDef) EachScript(yield func(Script) bool) {
 	}
 }
 
+// Parse parse script abbreviation or long name (with loose matching)
 func (d *ScriptDef) Parse(s string, aliasMap *AliasMap) (Script, error) {
 	if c, ok := d.abbrToScript[s]; ok {
 		return c, nil
@@ -245,18 +290,30 @@ func (d *ScriptDef) Parse(s string, aliasMap *AliasMap) (Script, error) {
 			}
 		}
 	}
-	return Script(0), fmt.Errorf("unknown script: %s", s)
+	if c, ok := d.looseToScript[looseMatchKey(s)]; ok {
+		return c, nil
+	}
+	if aliasMap != nil {
+		if c, ok := d.abbrToScript[aliasMap.LookupAbbrLoose(s)]; ok {
+			return c, nil
+		}
+	}
+	return Script(0), newUnknownPropertyValueError("script", s,
+		aliasCandidates(slices.Collect(maps.Keys(d.abbrToScript)), aliasMap))
 }
 
 func (d *ScriptDef) Unknown() Script {
 	return d.unknown
 }
 
-func (d *ScriptDef) Format(s Script, aliasMap *AliasMap) string {
+// Names returns abbreviation and long names
+func (d *ScriptDef) Names(s Script, aliasMap *AliasMap) []string {
 	abbr := d.GetAbbr(s)
-	tmp := []string{abbr}
-	tmp = append(tmp, aliasMap.Lookup(abbr)...)
-	return strings.Join(tmp, ", ")
+	return append([]string{abbr}, aliasMap.Lookup(abbr)...)
+}
+
+func (d *ScriptDef) Format(s Script, aliasMap *AliasMap) string {
+	return strings.Join(d.Names(s, aliasMap), ", ")
 }
 
 const ScriptExtensionPrefix = "scx"
@@ -267,27 +324,65 @@ func IsScriptExtensionPrefix(s string) bool {
 
 // PropertyDef common Unicode property definition
 type PropertyDef[T ~int] struct {
-	propertyToName []string
-	nameToProperty map[string]T
+	propertyToName  []string
+	nameToProperty  map[string]T
+	looseToProperty map[string]T
 }
 
 func NewPropertyDef[T ~int](names []string) *PropertyDef[T] {
 	ret := &PropertyDef[T]{
-		propertyToName: slices.Clone(names),
-		nameToProperty: make(map[string]T),
+		propertyToName:  slices.Clone(names),
+		nameToProperty:  make(map[string]T),
+		looseToProperty: make(map[string]T),
 	}
 	for i, name := range names {
 		p := T(i)
 		ret.nameToProperty[name] = p
+		if _, ok := ret.looseToProperty[looseMatchKey(name)]; !ok {
+			ret.looseToProperty[looseMatchKey(name)] = p
+		}
 	}
 	return ret
 }
 
+// NewExactPropertyDef create PropertyDef without loose matching.
+// used for numeric values (loose matching ignores hyphens, so `-1/2` would match `1/2`)
+func NewExactPropertyDef[T ~int](names []string) *PropertyDef[T] {
+	ret := NewPropertyDef[T](names)
+	clear(ret.looseToProperty)
+	return ret
+}
+
+// Parse parse property name (with loose matching)
 func (d *PropertyDef[T]) Parse(s string) (T, error) {
 	if p, ok := d.nameToProperty[s]; ok {
 		return p, nil
 	}
-	return T(0), fmt.Errorf("unknown property: %s", s)
+	if p, ok := d.looseToProperty[looseMatchKey(s)]; ok {
+		return p, nil
+	}
+	return T(0), newUnknownPropertyValueError("property", s, slices.Collect(maps.Keys(d.nameToProperty)))
+}
+
+// ParseWithAlias parse property value name or its alias (with loose matching)
+func (d *PropertyDef[T]) ParseWithAlias(s string, aliasMap *AliasMap) (T, error) {
+	if p, ok := d.nameToProperty[s]; ok {
+		return p, nil
+	}
+	if aliasMap != nil {
+		if p, ok := d.nameToProperty[aliasMap.LookupAbbr(s)]; ok {
+			return p, nil
+		}
+	}
+	if p, ok := d.looseToProperty[looseMatchKey(s)]; ok {
+		return p, nil
+	}
+	if aliasMap != nil {
+		if p, ok := d.nameToProperty[aliasMap.LookupAbbrLoose(s)]; ok {
+			return p, nil
+		}
+	}
+	return T(0), newUnknownPropertyValueError("property", s, aliasCandidates(d.propertyToName, aliasMap))
 }
 
 func (d *PropertyDef[T]) GetName(p T) string {
@@ -306,6 +401,16 @@ func (d *PropertyDef[T]) Format(p T) string {
 	return d.GetName(p)
 }
 
+// Names returns property value name and its aliases
+func (d *PropertyDef[T]) Names(p T, aliasMap *AliasMap) []string {
+	name := d.GetName(p)
+	return append([]string{name}, aliasMap.Lookup(name)...)
+}
+
+func (d *PropertyDef[T]) FormatWithAlias(p T, aliasMap *AliasMap) string {
+	return strings.Join(d.Names(p, aliasMap), ", ")
+}
+
 type PropList int
 
 const PropListPrefix = "prop"
@@ -370,9 +475,281 @@ func IsSentenceBreakPropertyPrefix(s string) bool {
 	return s == SentenceBreakPropPrefix
 }
 
+type IndicSyllabicCategory int
+
+const IndicSyllabicCategoryPrefix = "insc"
+
+func IsIndicSyllabicCategoryPrefix(s string) bool {
+	return s == IndicSyllabicCategoryPrefix
+}
+
+type IndicPositionalCategory int
+
+const IndicPositionalCategoryPrefix = "inpc"
+
+func IsIndicPositionalCategoryPrefix(s string) bool {
+	return s == IndicPositionalCategoryPrefix
+}
+
+type LineBreak int
+
+const LineBreakPrefix = "lb"
+
+func IsLineBreakPrefix(s string) bool {
+	return s == LineBreakPrefix
+}
+
+// LineBreakUnknown Line_Break value of code points not listed in LineBreak.txt
+const LineBreakUnknown = "XX"
+
+type VerticalOrientation int
+
+const VerticalOrientationPrefix = "vo"
+
+func IsVerticalOrientationPrefix(s string) bool {
+	return s == VerticalOrientationPrefix
+}
+
+// VerticalOrientationDefault Vertical_Orientation value of code points not listed in VerticalOrientation.txt
+const VerticalOrientationDefault = "R"
+
+type HangulSyllableType int
+
+const HangulSyllableTypePrefix = "hst"
+
+func IsHangulSyllableTypePrefix(s string) bool {
+	return s == HangulSyllableTypePrefix
+}
+
+// HangulSyllableTypeDefault Hangul_Syllable_Type value of code points not listed in HangulSyllableType.txt
+const HangulSyllableTypeDefault = "NA"
+
+type BidiPairedBracketType int
+
+const BidiPairedBracketTypePrefix = "bpt"
+
+func IsBidiPairedBracketTypePrefix(s string) bool {
+	return s == BidiPairedBracketTypePrefix
+}
+
+// bidiPairedBracketTypeNames Bidi_Paired_Bracket_Type abbreviations
+var bidiPairedBracketTypeNames = []string{"o", "c", "n"}
+
+// BidiPairedBracketTypeNone Bidi_Paired_Bracket_Type value of code points not listed in BidiBrackets.txt
+const BidiPairedBracketTypeNone = "n"
+
+type BidiClass int
+
+const BidiClassPrefix = "bc"
+
+func IsBidiClassPrefix(s string) bool {
+	return s == BidiClassPrefix
+}
+
+// bidiClassNames Bidi_Class abbreviations
+var bidiClassNames = []string{
+	"L", "R", "AL", "EN", "ES", "ET", "AN", "CS", "NSM", "BN", "B", "S", "WS", "ON",
+	"LRE", "LRO", "RLE", "RLO", "PDF", "LRI", "RLI", "FSI", "PDI",
+}
+
+// BidiClassDefault Bidi_Class value of code points not covered by @missing lines of DerivedBidiClass.txt
+const BidiClassDefault = "L"
+
+type CombiningClass int
+
+const CombiningClassPrefix = "ccc"
+
+func IsCombiningClassPrefix(s string) bool {
+	return s == CombiningClassPrefix
+}
+
+type DecompositionType int
+
+const DecompositionTypePrefix = "dt"
+
+func IsDecompositionTypePrefix(s string) bool {
+	return s == DecompositionTypePrefix
+}
+
+// decompositionTypeNames Decomposition_Type abbreviations
+var decompositionTypeNames = []string{
+	"Can", "Com", "Enc", "Fin", "Font", "Fra", "Init", "Iso", "Med",
+	"Nar", "Nb", "None", "Sml", "Sqr", "Sub", "Sup", "Vert", "Wide",
+}
+
+// decompositionTags decomposition tag in UnicodeData.txt to Decomposition_Type abbreviation
+var decompositionTags = map[string]string{
+	"font": "Font", "noBreak": "Nb", "initial": "Init", "medial": "Med", "final": "Fin",
+	"isolated": "Iso", "circle": "Enc", "super": "Sup", "sub": "Sub", "vertical": "Vert",
+	"wide": "Wide", "narrow": "Nar", "small": "Sml", "square": "Sqr", "fraction": "Fra", "compat": "Com",
+}
+
+type Age int
+
+const AgePrefix = "age"
+
+func IsAgePrefix(s string) bool {
+	return s == AgePrefix
+}
+
+// AgeUnassigned Age value of code points not listed in DerivedAge.txt
+const AgeUnassigned = "NA"
+
+// parseVersion parse Unicode version such as `15.0`, `15.1`
+func parseVersion(s string) ([]int, bool) {
+	ss := strings.Split(s, ".")
+	version := make([]int, 0, len(ss))
+	for _, e := range ss {
+		v, err := strconv.Atoi(e)
+		if err != nil || v < 0 {
+			return nil, false
+		}
+		version = append(version, v)
+	}
+	return version, true
+}
+
+// AgesUpTo returns ages assigned in or before version (version or its alias such as `V13_0`)
+func AgesUpTo(def *PropertyDef[Age], version string, aliasMap *AliasMap) ([]Age, error) {
+	if age, err := def.ParseWithAlias(version, aliasMap); err == nil {
+		version = def.GetName(age)
+	}
+	limit, ok := parseVersion(version)
+	if !ok {
+		return nil, fmt.Errorf("invalid version: %s", version)
+	}
+	var ages []Age
+	for age := range def.EachProperty {
+		if v, ok := parseVersion(def.GetName(age)); ok && slices.Compare(v, limit) <= 0 {
+			ages = append(ages, age)
+		}
+	}
+	return ages, nil
+}
+
+type Block int
+
+const BlockPrefix = "blk"
+
+func IsBlockPrefix(s string) bool {
+	return s == BlockPrefix
+}
+
+// BlockNoBlock Block value of code points not listed in Blocks.txt
+const BlockNoBlock = "No_Block"
+
+type JoiningType int
+
+const JoiningTypePrefix = "jt"
+
+func IsJoiningTypePrefix(s string) bool {
+	return s == JoiningTypePrefix
+}
+
+// JoiningTypeDefault Joining_Type value of code points not listed in DerivedJoiningType.txt (Non_Joining)
+const JoiningTypeDefault = "U"
+
+type JoiningGroup int
+
+const JoiningGroupPrefix = "jg"
+
+func IsJoiningGroupPrefix(s string) bool {
+	return s == JoiningGroupPrefix
+}
+
+// JoiningGroupDefault Joining_Group value of code points not listed in ArabicShaping.txt
+const JoiningGroupDefault = "No_Joining_Group"
+
+type NumericType int
+
+const NumericTypePrefix = "nt"
+
+func IsNumericTypePrefix(s string) bool {
+	return s == NumericTypePrefix
+}
+
+// numericTypeNames Numeric_Type abbreviations
+var numericTypeNames = []string{"De", "Di", "Nu", "None"}
+
+// NumericTypeNone Numeric_Type value of code points not listed in DerivedNumericType.txt
+const NumericTypeNone = "None"
+
+type NumericValue int
+
+const NumericValuePrefix = "nv"
+
+func IsNumericValuePrefix(s string) bool {
+	return s == NumericValuePrefix
+}
+
+// NumericValueNaN Numeric_Value of code points not listed in DerivedNumericValues.txt
+const NumericValueNaN = "NaN"
+
+// parseRational parse numeric value in rational form such as `5`, `1/2`, `-1/2`
+func parseRational(s string) (float64, error) {
+	numerator, denominator, found := strings.Cut(s, "/")
+	n, err := strconv.ParseFloat(numerator, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid numeric value: %s", s)
+	}
+	if !found {
+		return n, nil
+	}
+	d, err := strconv.ParseFloat(denominator, 64)
+	if err != nil || d == 0 {
+		return 0, fmt.Errorf("invalid numeric value: %s", s)
+	}
+	return n / d, nil
+}
+
+type IdentifierStatus int
+
+const IdentifierStatusPrefix = "idst"
+
+func IsIdentifierStatusPrefix(s string) bool {
+	return s == IdentifierStatusPrefix
+}
+
+// IdentifierStatusDefault Identifier_Status value of code points not listed in IdentifierStatus.txt
+const IdentifierStatusDefault = "Restricted"
+
+type IdentifierType int
+
+const IdentifierTypePrefix = "idty"
+
+func IsIdentifierTypePrefix(s string) bool {
+	return s == IdentifierTypePrefix
+}
+
+// IdentifierTypeDefault Identifier_Type value of code points not listed in IdentifierType.txt
+const IdentifierTypeDefault = "Not_Character"
+
+const NamePrefix = "name"
+
+func IsNamePrefix(s string) bool {
+	return s == NamePrefix
+}
+
+// propertyPrefixes all property prefixes (for error message)
+var propertyPrefixes = []string{
+	"cat", "gc", "ea", "eaw", ScriptPrefix, ScriptExtensionPrefix,
+	PropListPrefix, DerivedCorePropPrefix, EmojiPrefix, DerivedBinaryPropPrefix, DerivedNormalizationPropPrefix,
+	GraphemeBreakPropPrefix, WordBreakPropPrefix, SentenceBreakPropPrefix, LineBreakPrefix,
+	IndicSyllabicCategoryPrefix, IndicPositionalCategoryPrefix, VerticalOrientationPrefix, HangulSyllableTypePrefix,
+	BidiPairedBracketTypePrefix,
+	BidiClassPrefix, CombiningClassPrefix, DecompositionTypePrefix, AgePrefix, BlockPrefix,
+	JoiningTypePrefix, JoiningGroupPrefix, NumericTypePrefix, NumericValuePrefix, IdentifierStatusPrefix, IdentifierTypePrefix, NamePrefix,
+}
+
 func UnknowPropertyPrefixError(prefix string) string {
-	return fmt.Sprintf("unknown property prefix: %s, "+
-		"must be `cat`, `gc`, `ea`, `eaw`, `sc`, `scx`, "+
-		"`prop`, `dcp`, `emoji`, `dbp`, `dnp`"+
-		"`gbp`, `wbp` or `sbp`", prefix)
+	quoted := make([]string, 0, len(propertyPrefixes))
+	for _, p := range propertyPrefixes {
+		quoted = append(quoted, "`"+p+"`")
+	}
+	msg := fmt.Sprintf("unknown property prefix: %s, must be %s or %s", prefix,
+		strings.Join(quoted[:len(quoted)-1], ", "), quoted[len(quoted)-1])
+	if candidates := suggest(prefix, propertyPrefixes); len(candidates) > 0 {
+		msg += ", did you mean " + formatCandidates(candidates) + "?"
+	}
+	return msg
 }
@@ -0,0 +1,122 @@
+package op
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// looseMatchKey normalize name for loose matching (UAX44-LM3).
+// ignore case, whitespace, underscores, hyphens and leading "is"
+func looseMatchKey(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) || r == '_' || r == '-' {
+			return -1
+		}
+		return unicode.ToLower(r)
+	}, s)
+	if len(s) > 2 && strings.HasPrefix(s, "is") {
+		s = s[2:]
+	}
+	return s
+}
+
+// editDistance optimal string alignment distance between a and b
+// (Levenshtein distance with adjacent transposition)
+func editDistance(a string, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	d := make([][]int, len(ra)+1)
+	for i := range d {
+		d[i] = make([]int, len(rb)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(ra)][len(rb)]
+}
+
+const maxSuggestions = 3
+
+// suggest returns candidates close to s (at most maxSuggestions, closest first)
+func suggest(s string, candidates []string) []string {
+	key := looseMatchKey(s)
+	threshold := max(1, len([]rune(key))/3)
+	type scored struct {
+		name     string
+		distance int
+	}
+	var found []scored
+	for _, candidate := range candidates {
+		if d := editDistance(key, looseMatchKey(candidate)); d <= threshold {
+			found = append(found, scored{candidate, d})
+		}
+	}
+	slices.SortFunc(found, func(x, y scored) int {
+		return cmp.Or(cmp.Compare(x.distance, y.distance), cmp.Compare(x.name, y.name))
+	})
+	found = slices.CompactFunc(found, func(x, y scored) bool { return x.name == y.name })
+	var ret []string
+	for _, f := range found {
+		if len(ret) == maxSuggestions {
+			break
+		}
+		ret = append(ret, f.name)
+	}
+	return ret
+}
+
+// UnknownPropertyValueError unknown property value with suggested candidates
+type UnknownPropertyValueError struct {
+	Kind       string // general category, script, property, etc.
+	Value      string
+	Candidates []string
+}
+
+func formatCandidates(candidates []string) string {
+	quoted := make([]string, 0, len(candidates))
+	for _, candidate := range candidates {
+		quoted = append(quoted, "`"+candidate+"`")
+	}
+	last := len(quoted) - 1
+	if last == 0 {
+		return quoted[0]
+	}
+	return strings.Join(quoted[:last], ", ") + " or " + quoted[last]
+}
+
+func (e *UnknownPropertyValueError) Error() string {
+	msg := fmt.Sprintf("unknown %s: %s", e.Kind, e.Value)
+	if len(e.Candidates) > 0 {
+		msg += ", did you mean " + formatCandidates(e.Candidates) + "?"
+	}
+	return msg
+}
+
+func newUnknownPropertyValueError(kind string, value string, candidates []string) error {
+	return &UnknownPropertyValueError{Kind: kind, Value: value, Candidates: suggest(value, candidates)}
+}
+
+// aliasCandidates returns abbreviations and long names
+func aliasCandidates(abbrs []string, aliasMap *AliasMap) []string {
+	candidates := slices.Clone(abbrs)
+	if aliasMap != nil {
+		candidates = append(candidates, slices.Collect(maps.Keys(aliasMap.longToAbbr))...)
+	}
+	return candidates
+}
@@ -0,0 +1,148 @@
+package op
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLooseMatchKey(t *testing.T) {
+	assert.Equal(t, "whitespace", looseMatchKey("White_Space"))
+	assert.Equal(t, "whitespace", looseMatchKey("is-white space"))
+	assert.Equal(t, "lu", looseMatchKey("Lu"))
+	assert.Equal(t, "is", looseMatchKey("is"))
+}
+
+func TestEditDistance(t *testing.T) {
+	assert.Equal(t, 0, editDistance("abc", "abc"))
+	assert.Equal(t, 3, editDistance("", "abc"))
+	assert.Equal(t, 1, editDistance("alphabetc", "alphabetic"))
+	assert.Equal(t, 1, editDistance("ab", "ba"))
+	assert.Equal(t, 3, editDistance("abc", "ca")) // not allow edit of transposed substring
+	assert.Equal(t, 1, editDistance("αβ", "αγ"))
+}
+
+func TestSuggest(t *testing.T) {
+	candidates := []string{"Alphabetic", "Math", "Lowercase", "Uppercase", "ID_Start", "XID_Start"}
+	assert.Equal(t, []string{"Alphabetic"}, suggest("Alphabetc", candidates))
+	assert.Equal(t, []string{"Alphabetic"}, suggest("is_alphabetic", candidates))
+	assert.Equal(t, []string{"ID_Start", "XID_Start"}, suggest("IDStart", candidates))
+	assert.Empty(t, suggest("Emoji", candidates))
+
+	def := NewPropertyDef[PropList](candidates)
+	_, err := def.Parse("Upercase")
+	assert.Equal(t, "unknown property: Upercase, did you mean `Uppercase`?", err.Error())
+	_, err = def.Parse("Emoji")
+	assert.Equal(t, "unknown property: Emoji", err.Error())
+}
+
+func newTestDefRecord(aliasMaps *AliasMapRecord) *DefRecord {
+	aliasMaps.Script().AddAll("Latn", []string{"Latin"})
+	aliasMaps.Script().AddAll("Zzzz", []string{"Unknown"})
+	return &DefRecord{
+		ScriptDef:                   NewScriptDef([]string{"Latin"}, aliasMaps.Script()),
+		PropListDef:                 NewPropertyDef[PropList]([]string{"White_Space", "Dash"}),
+		DerivedCorePropDef:          NewPropertyDef[DerivedCoreProperty]([]string{"Alphabetic", "Math"}),
+		EmojiDef:                    NewPropertyDef[Emoji]([]string{"Emoji"}),
+		DerivedBinaryPropDef:        NewPropertyDef[DerivedBinaryProperty]([]string{"Bidi_Mirrored"}),
+		DerivedNormalizationPropDef: NewPropertyDef[DerivedNormalizationProp]([]string{"FC_NFKC"}),
+		GraphemeBreakPropDef:        NewPropertyDef[GraphemeBreakProperty]([]string{"Extend"}),
+		WordBreakPropDef:            NewPropertyDef[WordBreakProperty]([]string{"Extend", "ALetter"}),
+		SentenceBreakPropDef:        NewPropertyDef[SentenceBreakProperty]([]string{"Extend"}),
+		IndicSyllabicCategoryDef:    NewPropertyDef[IndicSyllabicCategory]([]string{"Bindu", "Vowel_Dependent"}),
+		IndicPositionalCategoryDef:  NewPropertyDef[IndicPositionalCategory]([]string{"Top", "Bottom"}),
+		VerticalOrientationDef:      NewPropertyDef[VerticalOrientation]([]string{"U", "Tu", "Tr", "R"}),
+		HangulSyllableTypeDef:       NewPropertyDef[HangulSyllableType]([]string{"L", "V", "T", "LV", "LVT", "NA"}),
+		BidiPairedBracketTypeDef:    NewPropertyDef[BidiPairedBracketType](bidiPairedBracketTypeNames),
+		LineBreakDef:                NewPropertyDef[LineBreak]([]string{"BA", "HY", "XX"}),
+		BidiClassDef:                NewPropertyDef[BidiClass](bidiClassNames),
+		CombiningClassDef:           NewPropertyDef[CombiningClass]([]string{"0", "1", "230"}),
+		DecompositionTypeDef:        NewPropertyDef[DecompositionType](decompositionTypeNames),
+		AgeDef:                      NewPropertyDef[Age]([]string{"1.1", "2.0", "13.0", "14.0", "15.0", "NA"}),
+		BlockDef:                    NewPropertyDef[Block]([]string{"ASCII", "Box_Drawing", "NB"}),
+		JoiningTypeDef:              NewPropertyDef[JoiningType]([]string{"D", "R", "T", "U"}),
+		JoiningGroupDef:             NewPropertyDef[JoiningGroup]([]string{"Beh", "African_Feh", "No_Joining_Group"}),
+		NumericTypeDef:              NewPropertyDef[NumericType](numericTypeNames),
+		NumericValueDef:             NewExactPropertyDef[NumericValue]([]string{"-1/2", "0", "1/2", "5", "NaN"}),
+		IdentifierStatusDef:         NewPropertyDef[IdentifierStatus]([]string{"Allowed", "Restricted"}),
+		IdentifierTypeDef:           NewPropertyDef[IdentifierType]([]string{"Recommended", "Inclusion", "Not_XID", "Not_Character"}),
+	}
+}
+
+func TestParserSuggestion(t *testing.T) {
+	aliasMaps := NewAliasMapRecord()
+	defRecord := newTestDefRecord(aliasMaps)
+	testCases := []struct {
+		src string
+		msg string
+	}{
+		{"dcp:Alphabetc", "unknown property: Alphabetc, did you mean `Alphabetic`?"},
+		{"prop:Alphabetic", "unknown property: Alphabetic, did you mean `dcp:Alphabetic`?"},
+		{"sc:Latn1", "unknown script: Latn1, did you mean `Latn`?"},
+		{"scx:Latim", "unknown script: Latim, did you mean `Latin`?"},
+		{"gbp:ALetter", "unknown property: ALetter, did you mean `wbp:ALetter`?"},
+		{"sbp:Extend + dbp:Extend", "unknown property: Extend, did you mean `gbp:Extend`, `wbp:Extend` or `sbp:Extend`?"},
+		{"ea:Math", "unknown east asian width: Math, did you mean `dcp:Math`?"},
+		{"insc:Top", "unknown property: Top, did you mean `inpc:Top`?"},
+		{"dpc:Math", "unknown property prefix: dpc, must be `cat`, `gc`, `ea`, `eaw`, `sc`, `scx`, `prop`, `dcp`, " +
+			"`emoji`, `dbp`, `dnp`, `gbp`, `wbp`, `sbp`, `lb`, `insc`, `inpc`, `vo`, `hst`, `bpt`, `bc`, `ccc`, `dt`, `age`, `blk`, `jt`, `jg`, `nt`, `nv`, `idst`, `idty` or `name`, did you mean `dcp`?"},
+	}
+	for _, testCase := range testCases {
+		_, err := NewParser(aliasMaps, defRecord).Run([]byte(testCase.src))
+		assert.Equal(t, "[syntax error] "+testCase.msg, firstLine(err), testCase.src)
+	}
+}
+
+func TestParserLooseMatching(t *testing.T) {
+	aliasMaps := NewAliasMapRecord()
+	aliasMaps.Category().AddAll("Lu", []string{"Uppercase_Letter"})
+	aliasMaps.Eaw().AddAll("W", []string{"Wide"})
+	defRecord := newTestDefRecord(aliasMaps)
+	defRecord.DerivedCorePropDef = NewPropertyDef[DerivedCoreProperty]([]string{"ID_Start", "Alphabetic"})
+
+	testCases := []struct {
+		src   string
+		check func(node Node)
+	}{
+		{"gc:uppercase_letter", func(node Node) {
+			assert.Equal(t, []GeneralCategory{CAT_Lu}, node.(*GeneralCategoryNode).properties)
+		}},
+		{"gc:lu", func(node Node) {
+			assert.Equal(t, []GeneralCategory{CAT_Lu}, node.(*GeneralCategoryNode).properties)
+		}},
+		{`ea:"is wide"`, func(node Node) {
+			assert.Equal(t, []EastAsianWidth{EAW_W}, node.(*EastAsianWidthNode).properties)
+		}},
+		{"sc:latin", func(node Node) {
+			assert.Equal(t, []Script{0}, node.(*ScriptNode).properties)
+		}},
+		{"sc:LATN", func(node Node) {
+			assert.Equal(t, []Script{0}, node.(*ScriptNode).properties)
+		}},
+		{"dcp:ID-Start,isAlphabetic", func(node Node) {
+			assert.Equal(t, []DerivedCoreProperty{0, 1}, node.(*PropertyNode[DerivedCoreProperty]).properties)
+		}},
+		{`dcp:"ID-Start"`, func(node Node) {
+			assert.Equal(t, []DerivedCoreProperty{0}, node.(*PropertyNode[DerivedCoreProperty]).properties)
+		}},
+		{"dcp:ID-Start - dcp:Alphabetic", func(node Node) {
+			assert.Equal(t, []DerivedCoreProperty{0}, node.(*DiffNode).left.(*PropertyNode[DerivedCoreProperty]).properties)
+		}},
+		{"gc:Lu-gc:Ll", func(node Node) { // still difference
+			assert.Equal(t, []GeneralCategory{CAT_Ll}, node.(*DiffNode).right.(*GeneralCategoryNode).properties)
+		}},
+	}
+	for _, testCase := range testCases {
+		node, err := NewParser(aliasMaps, defRecord).Run([]byte(testCase.src))
+		assert.Nil(t, err, testCase.src)
+		testCase.check(node)
+	}
+
+	// strict
+	_, err := NewParser(aliasMaps, defRecord).SetStrict(true).Run([]byte("gc:Uppercase_Letter + sc:Latin + dcp:ID_Start"))
+	assert.Nil(t, err)
+	_, err = NewParser(aliasMaps, defRecord).SetStrict(true).Run([]byte("sc:latin"))
+	assert.Equal(t, "[syntax error] unknown script: latin, did you mean `Latin` or `Latn`?", firstLine(err))
+	_, err = NewParser(aliasMaps, defRecord).SetStrict(true).Run([]byte("dcp:ID-Start"))
+	assert.Equal(t, "[syntax error] unknown property: ID-Start, did you mean `ID_Start`?", firstLine(err))
+}
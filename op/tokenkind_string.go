@@ -21,12 +21,21 @@ func _() {
 	_ = x[TokenMul-10]
 	_ = x[TokenAt-11]
 	_ = x[TokenRange-12]
-	_ = x[TokenSpace-13]
+	_ = x[TokenVar-13]
+	_ = x[TokenAssign-14]
+	_ = x[TokenSemicolon-15]
+	_ = x[TokenString-16]
+	_ = x[TokenRegex-17]
+	_ = x[TokenVersion-18]
+	_ = x[TokenLessEq-19]
+	_ = x[TokenRational-20]
+	_ = x[TokenSpace-21]
+	_ = x[TokenComment-22]
 }
 
-const _TokenKind_name = "EOSidentifiercodePoint:,()!+-*@..space"
+const _TokenKind_name = "EOSidentifiercodePoint:,()!+-*@..variable=;stringregexversion<=rationalspacecomment"
 
-var _TokenKind_index = [...]uint8{0, 3, 13, 22, 23, 24, 25, 26, 27, 28, 29, 30, 31, 33, 38}
+var _TokenKind_index = [...]uint8{0, 3, 13, 22, 23, 24, 25, 26, 27, 28, 29, 30, 31, 33, 41, 42, 43, 49, 54, 61, 63, 71, 76, 83}
 
 func (i TokenKind) String() string {
 	idx := int(i) - 0
@@ -0,0 +1,932 @@
+package op
+
+import (
+	"archive/zip"
+	"cmp"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/sekiguchi-nagisa/guniset/set"
+)
+
+// UCDXMLFiles file names of Unicode Character Database in XML (UAX #42). searched in this order
+var UCDXMLFiles = []string{
+	"ucd.all.flat.xml", "ucd.all.flat.zip", "ucd.nounihan.flat.xml", "ucd.nounihan.flat.zip",
+}
+
+// FindUCDXML returns path of UCDXML flat file in unicodeDir. if not found, returns empty string
+func FindUCDXML(unicodeDir string) string {
+	for _, name := range UCDXMLFiles {
+		p := path.Join(unicodeDir, name)
+		if info, err := os.Stat(p); err == nil && !info.IsDir() {
+			return p
+		}
+	}
+	return ""
+}
+
+// UCDXMLEntry code point (or code point range) element of repertoire
+type UCDXMLEntry struct {
+	set.RuneRange
+	Kind    string            // char, reserved, noncharacter or surrogate
+	Attrs   map[string]string // property attributes (inherited from group element)
+	Aliases []string          // from name-alias elements
+}
+
+// Listed returns true if entry is listed in UnicodeData.txt
+func (e *UCDXMLEntry) Listed() bool {
+	return e.Kind == "char" || e.Kind == "surrogate"
+}
+
+func (e *UCDXMLEntry) parseRange() error {
+	if cp, ok := e.Attrs["cp"]; ok {
+		r, err := set.ParseRune(cp)
+		if err != nil {
+			return err
+		}
+		e.RuneRange = set.RuneRange{First: r, Last: r}
+		return nil
+	}
+	first, err := set.ParseRune(e.Attrs["first-cp"])
+	if err != nil {
+		return err
+	}
+	last, err := set.ParseRune(e.Attrs["last-cp"])
+	if err != nil {
+		return err
+	}
+	e.RuneRange = set.RuneRange{First: first, Last: last}
+	return nil
+}
+
+type UCDXMLLoader struct {
+	name    string
+	reader  io.ReadCloser
+	decoder *xml.Decoder
+	header  DataHeader
+}
+
+type zipEntryReader struct {
+	io.ReadCloser
+	archive *zip.ReadCloser
+}
+
+func (z *zipEntryReader) Close() error {
+	return errors.Join(z.ReadCloser.Close(), z.archive.Close())
+}
+
+// NewUCDXMLLoader open UCDXML flat file. if p is zip file, open the first xml file in it
+func NewUCDXMLLoader(p string) (UCDXMLLoader, error) {
+	var reader io.ReadCloser
+	if strings.HasSuffix(p, ".zip") {
+		archive, err := zip.OpenReader(p)
+		if err != nil {
+			return UCDXMLLoader{}, err
+		}
+		index := slices.IndexFunc(archive.File, func(f *zip.File) bool {
+			return strings.HasSuffix(f.Name, ".xml")
+		})
+		if index < 0 {
+			_ = archive.Close()
+			return UCDXMLLoader{}, fmt.Errorf("%s: xml file not found", path.Base(p))
+		}
+		entry, err := archive.File[index].Open()
+		if err != nil {
+			_ = archive.Close()
+			return UCDXMLLoader{}, err
+		}
+		reader = &zipEntryReader{ReadCloser: entry, archive: archive}
+	} else {
+		f, err := os.Open(p)
+		if err != nil {
+			return UCDXMLLoader{}, err
+		}
+		reader = f
+	}
+	return UCDXMLLoader{
+		name:    path.Base(p),
+		reader:  reader,
+		decoder: xml.NewDecoder(reader),
+		header:  DataHeader{Filename: path.Base(p)},
+	}, nil
+}
+
+func (u *UCDXMLLoader) formatErr(e error) error {
+	line, _ := u.decoder.InputPos()
+	return fmt.Errorf("%s:%d: [load error] %s", u.name, line, e.Error())
+}
+
+// Load call callback for each code point element of repertoire
+func (u *UCDXMLLoader) Load(callback func(*UCDXMLEntry) error) error {
+	defer func(reader io.ReadCloser) {
+		_ = reader.Close()
+	}(u.reader)
+	var groupAttrs map[string]string
+	var entry *UCDXMLEntry
+	inRepertoire := false
+	inDescription := false
+	for {
+		token, err := u.decoder.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return u.formatErr(err)
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch name := t.Name.Local; {
+			case name == "description":
+				inDescription = true
+			case name == "repertoire":
+				inRepertoire = true
+			case name == "group" && inRepertoire:
+				groupAttrs = map[string]string{}
+				for _, attr := range t.Attr {
+					groupAttrs[attr.Name.Local] = attr.Value
+				}
+			case (name == "char" || name == "reserved" || name == "noncharacter" || name == "surrogate") && inRepertoire:
+				entry = &UCDXMLEntry{Kind: name, Attrs: maps.Clone(groupAttrs)}
+				if entry.Attrs == nil {
+					entry.Attrs = make(map[string]string, len(t.Attr))
+				}
+				for _, attr := range t.Attr {
+					entry.Attrs[attr.Name.Local] = attr.Value
+				}
+				if err := entry.parseRange(); err != nil {
+					return u.formatErr(err)
+				}
+			case name == "name-alias" && entry != nil:
+				for _, attr := range t.Attr {
+					if attr.Name.Local == "alias" {
+						entry.Aliases = append(entry.Aliases, attr.Value)
+					}
+				}
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "description":
+				inDescription = false
+			case "repertoire":
+				inRepertoire = false
+			case "group":
+				groupAttrs = nil
+			case "char", "reserved", "noncharacter", "surrogate":
+				if entry == nil {
+					continue
+				}
+				if err := callback(entry); err != nil {
+					return u.formatErr(err)
+				}
+				entry = nil
+			}
+		case xml.CharData:
+			if inDescription {
+				u.header.Created += strings.TrimSpace(string(t))
+			}
+		}
+	}
+	return nil
+}
+
+// ucdxmlValues code point ranges of each property value (in order of first appearance)
+type ucdxmlValues struct {
+	names    []string
+	builders map[string]*set.UniSetBuilder
+}
+
+func newUCDXMLValues() *ucdxmlValues {
+	return &ucdxmlValues{builders: map[string]*set.UniSetBuilder{}}
+}
+
+// define property value even if no code point has it
+func (v *ucdxmlValues) define(name string) {
+	if _, ok := v.builders[name]; !ok {
+		v.names = append(v.names, name)
+		v.builders[name] = &set.UniSetBuilder{}
+	}
+}
+
+func (v *ucdxmlValues) add(name string, runeRange set.RuneRange) {
+	v.define(name)
+	v.builders[name].AddRange(runeRange)
+}
+
+func buildUCDXMLPropertyMap[T ~int](values *ucdxmlValues) (*PropertyDef[T], UniSetMap[T]) {
+	setMap := UniSetMap[T]{}
+	for i, name := range values.names {
+		setMap[T(i)] = new(values.builders[name].Build())
+	}
+	return NewPropertyDef[T](values.names), setMap
+}
+
+// fillUnlisted add code points not contained in other property values to p
+func fillUnlisted[T comparable](setMap UniSetMap[T], p T) {
+	found := set.UniSetBuilder{}
+	for k, uniSet := range setMap {
+		if k != p {
+			found.AddSet(uniSet)
+		}
+	}
+	foundSet := found.Build()
+	if _, ok := setMap[p]; !ok {
+		setMap[p] = &set.UniSet{}
+	}
+	setMap[p].AddSet(new(foundSet.Complement()))
+}
+
+// ucdxmlProperty binary property attribute. code points are contained in property if match returns true
+type ucdxmlProperty struct {
+	attr  string
+	name  string // property name in UCD text files
+	match func(value string) bool
+}
+
+func isYes(value string) bool {
+	return value == "Y"
+}
+
+func isNotYes(value string) bool {
+	return value != "Y"
+}
+
+func isNotIdentity(value string) bool {
+	return value != "#"
+}
+
+// ucdxmlPropList properties of PropList.txt
+var ucdxmlPropList = []ucdxmlProperty{
+	{"WSpace", "White_Space", isYes}, {"Bidi_C", "Bidi_Control", isYes}, {"Join_C", "Join_Control", isYes},
+	{"Dash", "Dash", isYes}, {"Hyphen", "Hyphen", isYes}, {"QMark", "Quotation_Mark", isYes},
+	{"Term", "Terminal_Punctuation", isYes}, {"OMath", "Other_Math", isYes}, {"Hex", "Hex_Digit", isYes},
+	{"AHex", "ASCII_Hex_Digit", isYes}, {"OAlpha", "Other_Alphabetic", isYes}, {"Ideo", "Ideographic", isYes},
+	{"Dia", "Diacritic", isYes}, {"Ext", "Extender", isYes}, {"OLower", "Other_Lowercase", isYes},
+	{"OUpper", "Other_Uppercase", isYes}, {"NChar", "Noncharacter_Code_Point", isYes},
+	{"OGr_Ext", "Other_Grapheme_Extend", isYes}, {"IDSB", "IDS_Binary_Operator", isYes},
+	{"IDST", "IDS_Trinary_Operator", isYes}, {"IDSU", "IDS_Unary_Operator", isYes}, {"Radical", "Radical", isYes},
+	{"UIdeo", "Unified_Ideograph", isYes}, {"ODI", "Other_Default_Ignorable_Code_Point", isYes},
+	{"Dep", "Deprecated", isYes}, {"SD", "Soft_Dotted", isYes}, {"LOE", "Logical_Order_Exception", isYes},
+	{"OIDS", "Other_ID_Start", isYes}, {"OIDC", "Other_ID_Continue", isYes},
+	{"ID_Compat_Math_Continue", "ID_Compat_Math_Continue", isYes},
+	{"ID_Compat_Math_Start", "ID_Compat_Math_Start", isYes}, {"STerm", "Sentence_Terminal", isYes},
+	{"VS", "Variation_Selector", isYes}, {"Pat_WS", "Pattern_White_Space", isYes},
+	{"Pat_Syn", "Pattern_Syntax", isYes}, {"PCM", "Prepended_Concatenation_Mark", isYes},
+	{"RI", "Regional_Indicator", isYes}, {"MCM", "Modifier_Combining_Mark", isYes},
+}
+
+// ucdxmlDerivedCoreProps properties of DerivedCoreProperties.txt (except for InCB)
+var ucdxmlDerivedCoreProps = []ucdxmlProperty{
+	{"Math", "Math", isYes}, {"Alpha", "Alphabetic", isYes}, {"Lower", "Lowercase", isYes},
+	{"Upper", "Uppercase", isYes}, {"Cased", "Cased", isYes}, {"CI", "Case_Ignorable", isYes},
+	{"CWL", "Changes_When_Lowercased", isYes}, {"CWU", "Changes_When_Uppercased", isYes},
+	{"CWT", "Changes_When_Titlecased", isYes}, {"CWCF", "Changes_When_Casefolded", isYes},
+	{"CWCM", "Changes_When_Casemapped", isYes}, {"IDS", "ID_Start", isYes}, {"IDC", "ID_Continue", isYes},
+	{"XIDS", "XID_Start", isYes}, {"XIDC", "XID_Continue", isYes},
+	{"DI", "Default_Ignorable_Code_Point", isYes}, {"Gr_Ext", "Grapheme_Extend", isYes},
+	{"Gr_Base", "Grapheme_Base", isYes}, {"Gr_Link", "Grapheme_Link", isYes},
+}
+
+// ucdxmlEmojiProps properties of emoji-data.txt
+var ucdxmlEmojiProps = []ucdxmlProperty{
+	{"Emoji", "Emoji", isYes}, {"EPres", "Emoji_Presentation", isYes}, {"EMod", "Emoji_Modifier", isYes},
+	{"EBase", "Emoji_Modifier_Base", isYes}, {"EComp", "Emoji_Component", isYes},
+	{"ExtPict", "Extended_Pictographic", isYes},
+}
+
+// ucdxmlDerivedBinaryProps properties of DerivedBinaryProperties.txt
+var ucdxmlDerivedBinaryProps = []ucdxmlProperty{
+	{"Bidi_M", "Bidi_Mirrored", isYes},
+}
+
+// ucdxmlDerivedNormalizationProps properties of DerivedNormalizationProps.txt.
+// quick check properties contain code points whose values are not Y (N or M),
+// and mapping properties contain code points not mapped to themselves
+var ucdxmlDerivedNormalizationProps = []ucdxmlProperty{
+	{"FC_NFKC", "FC_NFKC", isNotIdentity}, {"Comp_Ex", "Full_Composition_Exclusion", isYes},
+	{"NFD_QC", "NFD_QC", isNotYes}, {"NFC_QC", "NFC_QC", isNotYes},
+	{"NFKD_QC", "NFKD_QC", isNotYes}, {"NFKC_QC", "NFKC_QC", isNotYes},
+	{"XO_NFD", "Expands_On_NFD", isYes}, {"XO_NFC", "Expands_On_NFC", isYes},
+	{"XO_NFKD", "Expands_On_NFKD", isYes}, {"XO_NFKC", "Expands_On_NFKC", isYes},
+	{"NFKC_CF", "NFKC_CF", isNotIdentity}, {"CWKCF", "Changes_When_NFKC_Casefolded", isYes},
+	{"NFKC_SCF", "NFKC_SCF", isNotIdentity},
+}
+
+// ucdxmlEnumAttrs attributes of enumerated properties (values are collected as is)
+var ucdxmlEnumAttrs = []string{
+	"gc", "ea", "sc", "GCB", "WB", "SB", "lb", "InSC", "InPC", "vo", "hst", "jt", "jg", "nt", "nv", "age", "blk", "bpt",
+}
+
+// unicodeDataRangeLabels labels of `<..., First>` entries in UnicodeData.txt that have no character names
+var unicodeDataRangeLabels = []NamedRange{
+	{RuneRange: set.RuneRange{First: 0xAC00, Last: 0xD7A3}, Label: "Hangul Syllable"},
+	{RuneRange: set.RuneRange{First: 0xD800, Last: 0xDB7F}, Label: "Non Private Use High Surrogate"},
+	{RuneRange: set.RuneRange{First: 0xDB80, Last: 0xDBFF}, Label: "Private Use High Surrogate"},
+	{RuneRange: set.RuneRange{First: 0xDC00, Last: 0xDFFF}, Label: "Low Surrogate"},
+	{RuneRange: set.RuneRange{First: 0xE000, Last: 0xF8FF}, Label: "Private Use"},
+	{RuneRange: set.RuneRange{First: 0xF0000, Last: 0xFFFFD}, Label: "Plane 15 Private Use"},
+	{RuneRange: set.RuneRange{First: 0x100000, Last: 0x10FFFD}, Label: "Plane 16 Private Use"},
+}
+
+// ucdxmlRecord intermediate state of properties loaded from UCDXML
+type ucdxmlRecord struct {
+	enums        map[string]*ucdxmlValues
+	scx          *ucdxmlValues
+	propList     *ucdxmlValues
+	derivedCore  *ucdxmlValues
+	emoji        *ucdxmlValues
+	derivedBin   *ucdxmlValues
+	derivedNorm  *ucdxmlValues
+	charData     *charDataBuilder
+	namedRanges  []NamedRange // found code points of unicodeDataRangeLabels
+	foldPairs    [][2]rune
+	fullFolds    RuneStringMap
+	mirroringMap RuneMap
+	unlisted     set.UniSetBuilder // code points not listed in UnicodeData.txt (reserved, noncharacter)
+}
+
+func newUCDXMLRecord() *ucdxmlRecord {
+	record := &ucdxmlRecord{
+		enums:        map[string]*ucdxmlValues{},
+		scx:          newUCDXMLValues(),
+		propList:     newUCDXMLValues(),
+		derivedCore:  newUCDXMLValues(),
+		emoji:        newUCDXMLValues(),
+		derivedBin:   newUCDXMLValues(),
+		derivedNorm:  newUCDXMLValues(),
+		charData:     newCharDataBuilder(),
+		fullFolds:    RuneStringMap{},
+		mirroringMap: RuneMap{},
+	}
+	for _, attr := range ucdxmlEnumAttrs {
+		record.enums[attr] = newUCDXMLValues()
+	}
+	return record
+}
+
+func addUCDXMLProperties(values *ucdxmlValues, props []ucdxmlProperty, entry *UCDXMLEntry) {
+	for _, prop := range props {
+		if value, ok := entry.Attrs[prop.attr]; ok && prop.match(value) {
+			values.add(prop.name, entry.RuneRange)
+		}
+	}
+}
+
+// parseCodePointsOrIdentity parse code points of mapping attribute. `#` means the code point itself (returns nil)
+func parseCodePointsOrIdentity(value string) ([]rune, error) {
+	if value == "#" {
+		return nil, nil
+	}
+	return parseCodePoints(value)
+}
+
+func (u *ucdxmlRecord) add(entry *UCDXMLEntry) error {
+	for _, attr := range ucdxmlEnumAttrs {
+		if value, ok := entry.Attrs[attr]; ok {
+			u.enums[attr].add(value, entry.RuneRange)
+		}
+	}
+	for _, s := range strings.Fields(entry.Attrs["scx"]) {
+		u.scx.add(s, entry.RuneRange)
+	}
+	addUCDXMLProperties(u.propList, ucdxmlPropList, entry)
+	addUCDXMLProperties(u.derivedCore, ucdxmlDerivedCoreProps, entry)
+	if value, ok := entry.Attrs["InCB"]; ok && value != "None" {
+		u.derivedCore.add("InCB_"+value, entry.RuneRange)
+	}
+	addUCDXMLProperties(u.emoji, ucdxmlEmojiProps, entry)
+	addUCDXMLProperties(u.derivedBin, ucdxmlDerivedBinaryProps, entry)
+	addUCDXMLProperties(u.derivedNorm, ucdxmlDerivedNormalizationProps, entry)
+
+	if value := entry.Attrs["bmg"]; value != "" && value != "#" {
+		mirrored, err := set.ParseRune(value)
+		if err != nil {
+			return err
+		}
+		u.mirroringMap[entry.First] = mirrored
+	}
+	if err := u.addCaseFolding(entry); err != nil {
+		return err
+	}
+	if entry.Listed() {
+		return u.addCharData(entry)
+	}
+	u.unlisted.AddRange(entry.RuneRange)
+	// unassigned code points have default Bidi_Class (such as R for Hebrew, AL for Arabic)
+	if bc, ok := entry.Attrs["bc"]; ok {
+		return u.charData.addBidiClass(entry.RuneRange, bc)
+	}
+	return nil
+}
+
+func (u *ucdxmlRecord) addCaseFolding(entry *UCDXMLEntry) error {
+	simple, err := parseCodePointsOrIdentity(entry.Attrs["scf"])
+	if err != nil {
+		return err
+	}
+	full, err := parseCodePointsOrIdentity(entry.Attrs["cf"])
+	if err != nil {
+		return err
+	}
+	folded := entry.First
+	if len(simple) == 1 {
+		folded = simple[0]
+		u.foldPairs = append(u.foldPairs, [2]rune{entry.First, folded})
+	}
+	if full != nil && !slices.Equal(full, []rune{folded}) { // status F
+		u.fullFolds[entry.First] = full
+	}
+	return nil
+}
+
+// addCharData add properties derived from UnicodeData.txt
+func (u *ucdxmlRecord) addCharData(entry *UCDXMLEntry) error {
+	builder := u.charData
+	ccc, err := strconv.Atoi(entry.Attrs["ccc"])
+	if err != nil {
+		return fmt.Errorf("invalid canonical combining class: %s", entry.Attrs["ccc"])
+	}
+	builder.addCombiningClass(entry.RuneRange, ccc)
+	if ccc != 0 {
+		for r := entry.First; r <= entry.Last; r++ {
+			builder.normalizer.SetCombiningClass(r, ccc)
+		}
+	}
+	if err := builder.addBidiClass(entry.RuneRange, entry.Attrs["bc"]); err != nil {
+		return err
+	}
+
+	if err := builder.addDecompositionType(entry.RuneRange, entry.Attrs["dt"]); err != nil {
+		return err
+	}
+	// Hangul syllables are decomposed algorithmically by Normalizer
+	if strings.EqualFold(entry.Attrs["dt"], "can") && !isHangulSyllable(entry.RuneRange) {
+		decomposition, err := parseCodePointsOrIdentity(entry.Attrs["dm"])
+		if err != nil {
+			return err
+		}
+		if decomposition != nil {
+			builder.normalizer.AddDecomposition(entry.First, decomposition)
+		}
+	}
+
+	// character names
+	switch name := entry.Attrs["na"]; {
+	case name != "":
+		for r := entry.First; r <= entry.Last; r++ {
+			builder.names.Add(r, strings.ReplaceAll(name, "#", fmt.Sprintf("%04X", r))) // such as `CJK UNIFIED IDEOGRAPH-#`
+		}
+	case entry.Attrs["gc"] == "Cc":
+		for r := entry.First; r <= entry.Last; r++ {
+			builder.names.Add(r, "<control>")
+		}
+	default:
+		index := slices.IndexFunc(unicodeDataRangeLabels, func(n NamedRange) bool {
+			return n.First <= entry.First && entry.Last <= n.Last
+		})
+		if index >= 0 {
+			label := unicodeDataRangeLabels[index].Label
+			if i := slices.IndexFunc(u.namedRanges, func(n NamedRange) bool { return n.Label == label }); i < 0 {
+				u.namedRanges = append(u.namedRanges, NamedRange{RuneRange: entry.RuneRange, Label: label})
+			} else {
+				u.namedRanges[i].First = min(u.namedRanges[i].First, entry.First)
+				u.namedRanges[i].Last = max(u.namedRanges[i].Last, entry.Last)
+			}
+		}
+	}
+	for _, alias := range entry.Aliases {
+		builder.names.AddAlias(entry.First, alias)
+	}
+
+	// full case mapping (unconditional)
+	for _, mapping := range []struct {
+		attr string
+		set  func(rune, []rune)
+	}{
+		{"uc", builder.caseMapping.SetUpper},
+		{"lc", builder.caseMapping.SetLower},
+		{"tc", builder.caseMapping.SetTitle},
+	} {
+		mapped, err := parseCodePointsOrIdentity(entry.Attrs[mapping.attr])
+		if err != nil {
+			return err
+		}
+		if mapped != nil {
+			mapping.set(entry.First, mapped)
+		}
+	}
+	return nil
+}
+
+// loadUCDXML load properties from UCDXML flat file
+func loadUCDXML(filename string, dbInfoList *DataHeaders) (*ucdxmlRecord, error) {
+	loader, err := NewUCDXMLLoader(filename)
+	if err != nil {
+		return nil, err
+	}
+	record := newUCDXMLRecord()
+	err = loader.Load(record.add)
+	if err != nil {
+		return nil, err
+	}
+	for _, namedRange := range record.namedRanges {
+		record.charData.names.AddRange(namedRange.RuneRange, namedRange.Label)
+	}
+	dbInfoList.List = append(dbInfoList.List, loader.header)
+	return record, nil
+}
+
+// toLongName replace abbreviation with the first long name defined in aliasMap
+func toLongName(values *ucdxmlValues, aliasMap *AliasMap) *ucdxmlValues {
+	ret := newUCDXMLValues()
+	for _, name := range values.names {
+		if longs := aliasMap.Lookup(name); len(longs) > 0 {
+			ret.names = append(ret.names, longs[0])
+			ret.builders[longs[0]] = values.builders[name]
+		} else {
+			ret.names = append(ret.names, name)
+			ret.builders[name] = values.builders[name]
+		}
+	}
+	return ret
+}
+
+func (u *ucdxmlRecord) generalCategoryMap() (UniSetMap[GeneralCategory], error) {
+	builderMap := map[GeneralCategory]*set.UniSetBuilder{}
+	for cate := range EachGeneralCategory {
+		builderMap[cate] = &set.UniSetBuilder{}
+	}
+	values := u.enums["gc"]
+	for _, name := range values.names {
+		cate, err := ParseGeneralCategory(name, nil)
+		if err != nil {
+			return nil, err
+		}
+		builderMap[cate].AddSet(new(values.builders[name].Build()))
+	}
+	setMap := UniSetMap[GeneralCategory]{}
+	for cate, builder := range builderMap {
+		setMap[cate] = new(builder.Build())
+	}
+	return setMap, nil
+}
+
+func (u *ucdxmlRecord) eastAsianWidthMap() (UniSetMap[EastAsianWidth], error) {
+	setMap := UniSetMap[EastAsianWidth]{}
+	for eaw := range EachEastAsianWidth {
+		setMap[eaw] = &set.UniSet{}
+	}
+	values := u.enums["ea"]
+	for _, name := range values.names {
+		eaw, err := ParseEastAsianWidth(name, nil)
+		if err != nil {
+			return nil, err
+		}
+		setMap[eaw].AddSet(new(values.builders[name].Build()))
+	}
+	fillUnlisted(setMap, EAW_N)
+	return setMap, nil
+}
+
+func (u *ucdxmlRecord) scriptMap(aliasMap *AliasMap) (*ScriptDef, UniSetMap[Script], UniSetMap[Script], error) {
+	values := toLongName(u.enums["sc"], aliasMap)
+	def := NewScriptDef(values.names, aliasMap)
+	setMap := UniSetMap[Script]{}
+	for i, name := range values.names {
+		setMap[Script(i)] = new(values.builders[name].Build())
+	}
+	if _, ok := setMap[def.Unknown()]; !ok {
+		setMap[def.Unknown()] = &set.UniSet{}
+	}
+
+	// Script_Extensions contains Script value except for Common and Inherited
+	scxMap := UniSetMap[Script]{}
+	for script := range setMap {
+		scxMap[script] = &set.UniSet{}
+	}
+	for _, name := range u.scx.names {
+		script, err := def.Parse(name, aliasMap)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		scxMap[script] = new(u.scx.builders[name].Build())
+	}
+	return def, setMap, scxMap, nil
+}
+
+// propertyMapWithDefault build property map and always define defaultValue
+func propertyMapWithDefault[T ~int](values *ucdxmlValues, defaultValue string) (*PropertyDef[T], UniSetMap[T]) {
+	values.define(defaultValue)
+	return buildUCDXMLPropertyMap[T](values)
+}
+
+func (u *ucdxmlRecord) ageMap() (*PropertyDef[Age], UniSetMap[Age], error) {
+	values := u.enums["age"]
+	var versions []string
+	for _, name := range values.names {
+		if name == "unassigned" {
+			continue
+		}
+		if _, ok := parseVersion(name); !ok {
+			return nil, nil, fmt.Errorf("invalid version: %s", name)
+		}
+		versions = append(versions, name)
+	}
+	slices.SortFunc(versions, func(x, y string) int {
+		vx, _ := parseVersion(x)
+		vy, _ := parseVersion(y)
+		return slices.Compare(vx, vy)
+	})
+	setMap := UniSetMap[Age]{}
+	for i, version := range versions {
+		setMap[Age(i)] = new(values.builders[version].Build())
+	}
+	fillUnlisted(setMap, Age(len(versions)))
+	return NewPropertyDef[Age](append(versions, AgeUnassigned)), setMap, nil
+}
+
+func (u *ucdxmlRecord) blockMap(aliasMap *AliasMap) (*PropertyDef[Block], UniSetMap[Block]) {
+	toAbbr := func(name string) string {
+		if abbr := aliasMap.LookupAbbrLoose(name); abbr != "" {
+			return abbr
+		}
+		return name
+	}
+	values := u.enums["blk"]
+	var names []string
+	setMap := UniSetMap[Block]{}
+	for _, name := range values.names {
+		if toAbbr(name) == toAbbr(BlockNoBlock) {
+			continue
+		}
+		setMap[Block(len(names))] = new(values.builders[name].Build())
+		names = append(names, toAbbr(name))
+	}
+	fillUnlisted(setMap, Block(len(names)))
+	return NewPropertyDef[Block](append(names, toAbbr(BlockNoBlock))), setMap
+}
+
+func (u *ucdxmlRecord) joiningGroupMap(aliasMap *AliasMap) (*PropertyDef[JoiningGroup], UniSetMap[JoiningGroup]) {
+	toAbbr := func(name string) string {
+		if abbr := aliasMap.LookupAbbrLoose(name); abbr != "" {
+			return abbr
+		}
+		return name
+	}
+	defaultName := toAbbr(JoiningGroupDefault)
+	values := u.enums["jg"]
+	var names []string
+	setMap := UniSetMap[JoiningGroup]{}
+	for _, name := range values.names {
+		if toAbbr(name) != defaultName {
+			setMap[JoiningGroup(len(names))] = new(values.builders[name].Build())
+			names = append(names, toAbbr(name))
+		}
+	}
+	fillUnlisted(setMap, JoiningGroup(len(names)))
+	return NewPropertyDef[JoiningGroup](append(names, defaultName)), setMap
+}
+
+// enumMapWithNone build property map of fixed values. code points of noneValue are complement of other values
+func enumMapWithNone[T ~int](values *ucdxmlValues, def *PropertyDef[T], noneValue string, aliasMap *AliasMap) (UniSetMap[T], error) {
+	none, _ := def.Parse(noneValue)
+	setMap := UniSetMap[T]{}
+	for _, name := range values.names {
+		p, err := def.ParseWithAlias(name, aliasMap)
+		if err != nil {
+			return nil, err
+		}
+		if p != none {
+			setMap[p] = new(values.builders[name].Build())
+		}
+	}
+	fillUnlisted(setMap, none)
+	return setMap, nil
+}
+
+func (u *ucdxmlRecord) numericValueMap() (*PropertyDef[NumericValue], UniSetMap[NumericValue], error) {
+	values := u.enums["nv"]
+	numbers := map[string]float64{}
+	for _, name := range values.names {
+		if name == NumericValueNaN {
+			continue
+		}
+		v, err := parseRational(name)
+		if err != nil {
+			return nil, nil, err
+		}
+		numbers[name] = v
+	}
+	names := slices.SortedFunc(maps.Keys(numbers), func(x, y string) int {
+		return cmp.Compare(numbers[x], numbers[y])
+	})
+	setMap := UniSetMap[NumericValue]{}
+	for i, name := range names {
+		setMap[NumericValue(i)] = new(values.builders[name].Build())
+	}
+	fillUnlisted(setMap, NumericValue(len(names)))
+	return NewExactPropertyDef[NumericValue](append(names, NumericValueNaN)), setMap, nil
+}
+
+func (u *ucdxmlRecord) caseFoldingMap() *CaseFoldMap {
+	caseFoldingMap := NewCaseFoldMap(u.foldPairs)
+	for r, runes := range u.fullFolds {
+		caseFoldingMap.AddFull(r, runes)
+	}
+	// UCDXML does not have Turkic case folding (status T of CaseFolding.txt).
+	// if CaseFolding.txt is not found, use mappings of Unicode 16.0 (may be outdated in the future)
+	caseFoldingMap.AddTurkic(0x0049, 0x0131)
+	caseFoldingMap.AddTurkic(0x0130, 0x0069)
+	return caseFoldingMap
+}
+
+// loadTurkicCaseFolding replace Turkic case folding of caseFoldingMap with CaseFolding.txt if exists
+func loadTurkicCaseFolding(filename string, caseFoldingMap *CaseFoldMap, dbInfoList *DataHeaders) error {
+	if _, err := os.Stat(filename); err != nil {
+		return nil
+	}
+	foldMap, err := LoadCaseFoldingMap(filename, dbInfoList)
+	if err != nil {
+		return err
+	}
+	caseFoldingMap.turkic = foldMap.turkic
+	return nil
+}
+
+// NewEvalContextFromUCDXML load properties from data.UCDXML instead of UCD text files.
+// PropertyValueAliases.txt, emoji sequences and UTS #39 data are still loaded from text files
+func NewEvalContextFromUCDXML(data *UnicodeData) (*EvalContext, error) {
+	headers := DataHeaders{}
+	record, err := loadUCDXML(data.UCDXML, &headers)
+	if err != nil {
+		return nil, err
+	}
+	aliasMaps, err := LoadTargetAliasMap(data.PropertyValueAliases, &headers)
+	if err != nil {
+		return nil, err
+	}
+	catMap, err := record.generalCategoryMap()
+	if err != nil {
+		return nil, err
+	}
+	eawMap, err := record.eastAsianWidthMap()
+	if err != nil {
+		return nil, err
+	}
+	scriptDef, scriptMap, scriptXMap, err := record.scriptMap(aliasMaps.Script())
+	if err != nil {
+		return nil, err
+	}
+	propDef, propListMap := buildUCDXMLPropertyMap[PropList](record.propList)
+	derivedCorePropDef, derivedCorePropMap := buildUCDXMLPropertyMap[DerivedCoreProperty](record.derivedCore)
+	emojiDef, emojiMap := buildUCDXMLPropertyMap[Emoji](record.emoji)
+	derivedBinaryPropDef, derivedBinaryPropMap := buildUCDXMLPropertyMap[DerivedBinaryProperty](record.derivedBin)
+	derivedNormPropDef, derivedNormPropMap := buildUCDXMLPropertyMap[DerivedNormalizationProp](record.derivedNorm)
+	graphemePropDef, graphemePropMap := buildUCDXMLPropertyMap[GraphemeBreakProperty](
+		toLongName(record.enums["GCB"], aliasMaps.GraphemeBreak()))
+	wordPropDef, wordPropMap := buildUCDXMLPropertyMap[WordBreakProperty](
+		toLongName(record.enums["WB"], aliasMaps.WordBreak()))
+	sentencePropDef, sentencePropMap := buildUCDXMLPropertyMap[SentenceBreakProperty](
+		toLongName(record.enums["SB"], aliasMaps.SentenceBreak()))
+	lineBreakDef, lineBreakMap := propertyMapWithDefault[LineBreak](record.enums["lb"], LineBreakUnknown)
+	inscDef, inscMap := buildUCDXMLPropertyMap[IndicSyllabicCategory](record.enums["InSC"])
+	inpcDef, inpcMap := buildUCDXMLPropertyMap[IndicPositionalCategory](record.enums["InPC"])
+	voDef, voMap := propertyMapWithDefault[VerticalOrientation](record.enums["vo"], VerticalOrientationDefault)
+	hstDef, hstMap := propertyMapWithDefault[HangulSyllableType](record.enums["hst"], HangulSyllableTypeDefault)
+	jtDef, jtMap := propertyMapWithDefault[JoiningType](record.enums["jt"], JoiningTypeDefault)
+	jgDef, jgMap := record.joiningGroupMap(aliasMaps.JoiningGroup())
+	bptDef := NewPropertyDef[BidiPairedBracketType](bidiPairedBracketTypeNames)
+	bptMap, err := enumMapWithNone(record.enums["bpt"], bptDef, BidiPairedBracketTypeNone, nil)
+	if err != nil {
+		return nil, err
+	}
+	ntDef := NewPropertyDef[NumericType](numericTypeNames)
+	ntMap, err := enumMapWithNone(record.enums["nt"], ntDef, NumericTypeNone, aliasMaps.NumericType())
+	if err != nil {
+		return nil, err
+	}
+	nvDef, nvMap, err := record.numericValueMap()
+	if err != nil {
+		return nil, err
+	}
+	ageDef, ageMap, err := record.ageMap()
+	if err != nil {
+		return nil, err
+	}
+	blockDef, blockMap := record.blockMap(aliasMaps.Block())
+	charData := record.charData.build()
+	caseFoldingMap := record.caseFoldingMap()
+	err = loadTurkicCaseFolding(data.CaseFolding, caseFoldingMap, &headers)
+	if err != nil {
+		return nil, err
+	}
+
+	// not included in UCDXML
+	idstDef, idstMap, err := LoadPropertyMapWithDefault[IdentifierStatus](data.IdentifierStatus,
+		IdentifierStatusDefault, nil, &headers)
+	if err != nil {
+		return nil, err
+	}
+	idtyDef, idtyMap, err := LoadIdentifierTypeMap(data.IdentifierType, &headers)
+	if err != nil {
+		return nil, err
+	}
+	confusables, err := LoadConfusables(data.Confusables, &headers)
+	if err != nil {
+		return nil, err
+	}
+	stringPropertyMap := make(StringPropertyMap)
+	err = LoadStringPropertyMap(data.EmojiSequences, &headers, stringPropertyMap)
+	if err != nil {
+		return nil, err
+	}
+	err = LoadStringPropertyMap(data.EmojiZwjSequences, &headers, stringPropertyMap)
+	if err != nil {
+		return nil, err
+	}
+	unlisted := record.unlisted.Build()
+	unlistedMap := map[string]*set.UniSet{
+		EastAsianWidthPrefix:    &unlisted,
+		ScriptPrefix:            &unlisted,
+		GraphemeBreakPropPrefix: &unlisted,
+		WordBreakPropPrefix:     &unlisted,
+		SentenceBreakPropPrefix: &unlisted,
+	}
+
+	return &EvalContext{
+		Headers:        headers,
+		CateMap:        catMap,
+		EawMap:         eawMap,
+		AliasMapRecord: aliasMaps,
+		DefRecord: DefRecord{
+			ScriptDef:                   scriptDef,
+			PropListDef:                 propDef,
+			DerivedCorePropDef:          derivedCorePropDef,
+			EmojiDef:                    emojiDef,
+			DerivedBinaryPropDef:        derivedBinaryPropDef,
+			DerivedNormalizationPropDef: derivedNormPropDef,
+			GraphemeBreakPropDef:        graphemePropDef,
+			WordBreakPropDef:            wordPropDef,
+			SentenceBreakPropDef:        sentencePropDef,
+			LineBreakDef:                lineBreakDef,
+			IndicSyllabicCategoryDef:    inscDef,
+			IndicPositionalCategoryDef:  inpcDef,
+			VerticalOrientationDef:      voDef,
+			HangulSyllableTypeDef:       hstDef,
+			BidiClassDef:                charData.BidiClassDef,
+			CombiningClassDef:           charData.CombiningClassDef,
+			DecompositionTypeDef:        charData.DecompositionTypeDef,
+			BidiPairedBracketTypeDef:    bptDef,
+			AgeDef:                      ageDef,
+			BlockDef:                    blockDef,
+			JoiningTypeDef:              jtDef,
+			JoiningGroupDef:             jgDef,
+			NumericTypeDef:              ntDef,
+			NumericValueDef:             nvDef,
+			IdentifierStatusDef:         idstDef,
+			IdentifierTypeDef:           idtyDef,
+		},
+		ScriptMap:                   scriptMap,
+		ScriptXMap:                  scriptXMap,
+		PropListMap:                 propListMap,
+		DerivedCorePropMap:          derivedCorePropMap,
+		EmojiMap:                    emojiMap,
+		DerivedBinaryPropMap:        derivedBinaryPropMap,
+		DerivedNormalizationPropMap: derivedNormPropMap,
+		GraphemeBreakPropMap:        graphemePropMap,
+		WordBreakPropMap:            wordPropMap,
+		SentenceBreakPropMap:        sentencePropMap,
+		LineBreakMap:                lineBreakMap,
+		IndicSyllabicCategoryMap:    inscMap,
+		IndicPositionalCategoryMap:  inpcMap,
+		VerticalOrientationMap:      voMap,
+		HangulSyllableTypeMap:       hstMap,
+		BidiClassMap:                charData.BidiClassMap,
+		CombiningClassMap:           charData.CombiningClassMap,
+		DecompositionTypeMap:        charData.DecompositionTypeMap,
+		BidiPairedBracketTypeMap:    bptMap,
+		BidiMirroringMap:            record.mirroringMap,
+		AgeMap:                      ageMap,
+		BlockMap:                    blockMap,
+		JoiningTypeMap:              jtMap,
+		JoiningGroupMap:             jgMap,
+		NumericTypeMap:              ntMap,
+		NumericValueMap:             nvMap,
+		IdentifierStatusMap:         idstMap,
+		IdentifierTypeMap:           idtyMap,
+		UnlistedMap:                 unlistedMap,
+		CharNames:                   charData.Names,
+		CaseFoldingMap:              caseFoldingMap,
+		CaseMapping:                 charData.CaseMapping,
+		Normalizer:                  charData.Normalizer,
+		Confusables:                 confusables,
+		StringPropertyMap:           stringPropertyMap,
+	}, nil
+}
@@ -0,0 +1,169 @@
+package op
+
+import (
+	"archive/zip"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testUCDXML = `<?xml version="1.0" encoding="UTF-8"?>
+<ucd xmlns="http://www.unicode.org/ns/2003/ucd/1.0">
+<description>Unicode 16.0.0</description>
+<repertoire>
+<char cp="0009" age="1.1" na="" gc="Cc" ccc="0" bc="S" dt="none" dm="#" sc="Zyyy" scx="Zyyy" lb="BA" ea="N" blk="ASCII" WSpace="Y" uc="#" lc="#" tc="#" scf="#" cf="#" GCB="CN">
+<name-alias alias="CHARACTER TABULATION" type="control"/>
+<name-alias alias="TAB" type="abbreviation"/>
+</char>
+<group age="1.1" gc="Lu" ccc="0" bc="L" dt="none" dm="#" sc="Latn" scx="Latn" lb="AL" ea="Na" blk="ASCII" WSpace="N" GCB="XX">
+<char cp="0041" na="LATIN CAPITAL LETTER A" uc="#" lc="0061" tc="#" scf="0061" cf="0061"/>
+<char cp="00C0" na="LATIN CAPITAL LETTER A WITH GRAVE" ea="A" dt="can" dm="0041 0300" blk="Latin_1_Sup" uc="#" lc="00E0" tc="#" scf="00E0" cf="00E0"/>
+</group>
+<char cp="00DF" age="1.1" na="LATIN SMALL LETTER SHARP S" gc="Ll" ccc="0" bc="L" dt="none" dm="#" sc="Latn" scx="Latn" lb="AL" ea="A" blk="Latin_1_Sup" uc="0053 0053" lc="#" tc="0053 0073" scf="#" cf="0073 0073" GCB="XX"/>
+<char cp="0300" age="1.1" na="COMBINING GRAVE ACCENT" gc="Mn" ccc="230" bc="NSM" dt="none" dm="#" sc="Zinh" scx="Zinh" lb="CM" ea="A" blk="Diacriticals" uc="#" lc="#" tc="#" scf="#" cf="#" GCB="EX"/>
+<reserved first-cp="0378" last-cp="0379" age="unassigned" na="" gc="Cn" ccc="0" bc="L" dt="none" dm="#" sc="Zzzz" scx="Zzzz" lb="XX" ea="N" blk="Greek" GCB="XX"/>
+<reserved first-cp="05C8" last-cp="05CF" age="unassigned" na="" gc="Cn" ccc="0" bc="R" dt="none" dm="#" sc="Zzzz" scx="Zzzz" lb="XX" ea="N" blk="Hebrew" GCB="XX"/>
+<char first-cp="3400" last-cp="4DBF" age="3.0" na="CJK UNIFIED IDEOGRAPH-#" gc="Lo" ccc="0" bc="L" dt="none" dm="#" sc="Hani" scx="Hani" lb="ID" ea="W" blk="CJK_Ext_A" uc="#" lc="#" tc="#" scf="#" cf="#" GCB="XX"/>
+<char cp="AC00" age="2.0" na="" gc="Lo" ccc="0" bc="L" dt="can" dm="1100 1161" sc="Hang" scx="Hang" lb="H2" ea="W" blk="Hangul" uc="#" lc="#" tc="#" scf="#" cf="#" GCB="LV"/>
+<char first-cp="E000" last-cp="F8FF" age="1.1" na="" gc="Co" ccc="0" bc="L" dt="none" dm="#" sc="Zzzz" scx="Zzzz" lb="XX" ea="A" blk="PUA" uc="#" lc="#" tc="#" scf="#" cf="#" GCB="XX"/>
+</repertoire>
+</ucd>
+`
+
+func TestLoadUCDXML(t *testing.T) {
+	p := writeFile(t, t.TempDir(), "ucd.all.flat.xml", testUCDXML)
+	assert.Equal(t, p, FindUCDXML(path.Dir(p)))
+	headers := DataHeaders{}
+	record, err := loadUCDXML(p, &headers)
+	assert.Nil(t, err)
+	assert.Equal(t, []DataHeader{{Filename: "ucd.all.flat.xml", Created: "Unicode 16.0.0"}}, headers.List)
+
+	aliasMaps := NewAliasMapRecord()
+	assert.Nil(t, aliasMaps.Define(ScriptPrefix, "Latn", []string{"Latin"}))
+	assert.Nil(t, aliasMaps.Define(ScriptPrefix, "Zinh", []string{"Inherited", "Qaai"}))
+	assert.Nil(t, aliasMaps.Define(ScriptPrefix, "Zzzz", []string{"Unknown"}))
+	assert.Nil(t, aliasMaps.Define(graphemeBreakAliasTarget, "XX", []string{"Other"}))
+	assert.Nil(t, aliasMaps.Define(graphemeBreakAliasTarget, "EX", []string{"Extend"}))
+
+	// general category
+	cateMap, err := record.generalCategoryMap()
+	assert.Nil(t, err)
+	assert.Equal(t, "{0x0041..0x0041,0x00c0..0x00c0}", cateMap[CAT_Lu].String())
+	assert.Equal(t, "{0x0378..0x0379,0x05c8..0x05cf}", cateMap[CAT_Cn].String())
+
+	// script (converted to long names)
+	scriptDef, scriptMap, scriptXMap, err := record.scriptMap(aliasMaps.Script())
+	assert.Nil(t, err)
+	latin, err := scriptDef.Parse("Latin", aliasMaps.Script())
+	assert.Nil(t, err)
+	assert.Equal(t, "{0x0041..0x0041,0x00c0..0x00c0,0x00df..0x00df}", scriptMap[latin].String())
+	assert.Equal(t, "{0x0041..0x0041,0x00c0..0x00c0,0x00df..0x00df}", scriptXMap[latin].String())
+	assert.Equal(t, "{0x0378..0x0379,0x05c8..0x05cf,0xe000..0xf8ff}", scriptMap[scriptDef.Unknown()].String())
+
+	// property list
+	propDef, propMap := buildUCDXMLPropertyMap[PropList](record.propList)
+	assert.Equal(t, []string{"White_Space"}, propDef.propertyToName)
+	assert.Equal(t, "{0x0009..0x0009}", propMap[0].String())
+
+	// grapheme break (converted to long names)
+	gcbDef, gcbMap := buildUCDXMLPropertyMap[GraphemeBreakProperty](
+		toLongName(record.enums["GCB"], aliasMaps.GraphemeBreak()))
+	assert.Equal(t, []string{"CN", "Other", "Extend", "LV"}, gcbDef.propertyToName)
+	assert.Equal(t, "{0x0300..0x0300}", gcbMap[2].String())
+
+	// age
+	ageDef, ageMap, err := record.ageMap()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"1.1", "2.0", "3.0", "NA"}, ageDef.propertyToName)
+	assert.True(t, ageMap[3].Find(0x378))
+	assert.False(t, ageMap[3].Find(0xAC00))
+	assert.Equal(t, "{0xac00..0xac00}", ageMap[1].String())
+
+	// char data
+	charData := record.charData.build()
+	dt, err := charData.DecompositionTypeDef.Parse("Can")
+	assert.Nil(t, err)
+	assert.Equal(t, "{0x00c0..0x00c0,0xac00..0xac00}", charData.DecompositionTypeMap[dt].String())
+	dt, err = charData.DecompositionTypeDef.Parse("None")
+	assert.Nil(t, err)
+	assert.False(t, charData.DecompositionTypeMap[dt].Find(0xAC00))
+	assert.Equal(t, []rune{0x1100, 0x1161}, charData.Normalizer.NFD([]rune{0xAC00}))
+	ccc, err := charData.CombiningClassDef.Parse("230")
+	assert.Nil(t, err)
+	assert.Equal(t, "{0x0300..0x0300}", charData.CombiningClassMap[ccc].String())
+	bc, err := charData.BidiClassDef.Parse("L")
+	assert.Nil(t, err)
+	assert.Equal(t, "{0x0000..0x0008,0x000a..0x02ff,0x0301..0x05c7,0x05d0..0x10ffff}",
+		charData.BidiClassMap[bc].String()) // unlisted code points are L
+	bc, err = charData.BidiClassDef.Parse("R")
+	assert.Nil(t, err)
+	assert.Equal(t, "{0x05c8..0x05cf}", charData.BidiClassMap[bc].String()) // reserved code points have default value
+	assert.Equal(t, []rune{0x41, 0x300}, charData.Normalizer.NFD([]rune{0xC0}))
+
+	// names
+	for _, e := range []struct {
+		r    rune
+		name string
+	}{
+		{0x09, "<control>"},
+		{0x41, "LATIN CAPITAL LETTER A"},
+		{0x3500, "CJK UNIFIED IDEOGRAPH-3500"},
+		{0xAC00, "HANGUL SYLLABLE GA"},
+		{0xE001, "<Private Use>"},
+	} {
+		name, ok := charData.Names.Lookup(e.r)
+		assert.True(t, ok)
+		assert.Equal(t, e.name, name)
+	}
+	_, ok := charData.Names.Lookup(0x378)
+	assert.False(t, ok)
+	assert.Equal(t, []string{"CHARACTER TABULATION", "TAB"}, charData.Names.Aliases(0x09))
+
+	// case mapping and folding
+	assert.Equal(t, []rune{0x53, 0x53}, charData.CaseMapping.LookupUpper(0xDF))
+	assert.Equal(t, []rune{0x53, 0x73}, charData.CaseMapping.LookupTitle(0xDF))
+	assert.Equal(t, []rune{0x61}, charData.CaseMapping.LookupLower(0x41))
+	caseFoldMap := record.caseFoldingMap()
+	assert.Equal(t, 'a', caseFoldMap.LookupFold('A'))
+	assert.Equal(t, []rune{'a', 'A'}, caseFoldMap.Closure('a', false))
+	assert.Equal(t, []rune{0x73, 0x73}, caseFoldMap.LookupFullFold(0xDF))
+	assert.ElementsMatch(t, []rune{0x49, 0x131}, caseFoldMap.Closure(0x49, true))
+
+	// Turkic case folding from CaseFolding.txt
+	assert.Nil(t, loadTurkicCaseFolding(path.Join(t.TempDir(), "CaseFolding.txt"), caseFoldMap, &headers))
+	assert.Len(t, headers.List, 1)
+	p = writeFile(t, t.TempDir(), "CaseFolding.txt", "0041; T; 0062; # dummy\n")
+	assert.Nil(t, loadTurkicCaseFolding(p, caseFoldMap, &headers))
+	assert.ElementsMatch(t, []rune{0x41, 0x62}, caseFoldMap.Closure(0x41, true))
+	assert.ElementsMatch(t, []rune{0x130}, caseFoldMap.Closure(0x130, true)) // replaced
+}
+
+func TestLoadUCDXMLZip(t *testing.T) {
+	dir := t.TempDir()
+	p := path.Join(dir, "ucd.all.flat.zip")
+	file, err := os.Create(p)
+	assert.Nil(t, err)
+	writer := zip.NewWriter(file)
+	w, err := writer.Create("ucd.all.flat.xml")
+	assert.Nil(t, err)
+	_, err = w.Write([]byte(testUCDXML))
+	assert.Nil(t, err)
+	assert.Nil(t, writer.Close())
+	assert.Nil(t, file.Close())
+
+	assert.Equal(t, p, FindUCDXML(dir))
+	headers := DataHeaders{}
+	record, err := loadUCDXML(p, &headers)
+	assert.Nil(t, err)
+	assert.Equal(t, []DataHeader{{Filename: "ucd.all.flat.zip", Created: "Unicode 16.0.0"}}, headers.List)
+	cateMap, err := record.generalCategoryMap()
+	assert.Nil(t, err)
+	assert.Equal(t, "{0x0300..0x0300}", cateMap[CAT_Mn].String())
+
+	// broken xml
+	p = writeFile(t, dir, "ucd.all.flat.xml", "<ucd><repertoire><char cp=\"ZZ\"/></repertoire></ucd>")
+	_, err = loadUCDXML(p, &headers)
+	assert.NotNil(t, err)
+}
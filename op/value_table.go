@@ -0,0 +1,175 @@
+package op
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/sekiguchi-nagisa/guniset/set"
+)
+
+// PropertyValueTable enumerated property values and corresponding code point sets
+type PropertyValueTable struct {
+	Prefix   string
+	Names    []string      // property value names (index is value id)
+	Sets     []*set.UniSet // code point sets (index is value id)
+	Default  int           // value id of unlisted code points
+	Unlisted *set.UniSet   // code points not listed in data file (values of @missing lines). may be nil
+}
+
+// ValueRange code point range tagged with property value id
+type ValueRange struct {
+	set.RuneRange
+	Value int
+}
+
+const defaultPropertyValueName = "Other"
+
+func newPropertyValueTable[T ~int](prefix string, def *PropertyDef[T], setMap UniSetMap[T]) *PropertyValueTable {
+	table := &PropertyValueTable{Prefix: prefix}
+	for p := range def.EachProperty {
+		table.Names = append(table.Names, def.Format(p))
+		table.Sets = append(table.Sets, setMap[p])
+	}
+	table.Default = slices.Index(table.Names, defaultPropertyValueName)
+	if table.Default < 0 { // not found, append default value
+		table.Default = len(table.Names)
+		table.Names = append(table.Names, defaultPropertyValueName)
+		table.Sets = append(table.Sets, &set.UniSet{})
+	}
+	return table
+}
+
+// NewPropertyValueTable build property value table of enumerated property
+// (gc, ea, sc, gbp, wbp, sbp)
+func (e *EvalContext) NewPropertyValueTable(prefix string) (*PropertyValueTable, error) {
+	table, err := e.newPropertyValueTable(prefix)
+	if err != nil {
+		return nil, err
+	}
+	table.Unlisted = e.UnlistedMap[table.Prefix]
+	return table, nil
+}
+
+func (e *EvalContext) newPropertyValueTable(prefix string) (*PropertyValueTable, error) {
+	switch {
+	case IsGeneralCategoryPrefix(prefix):
+		table := &PropertyValueTable{Prefix: GeneralCategoryPrefix, Default: int(CAT_Cn)}
+		for cat := range EachGeneralCategory {
+			table.Names = append(table.Names, cat.String())
+			table.Sets = append(table.Sets, e.CateMap[cat])
+		}
+		return table, nil
+	case IsEastAsianWidthPrefix(prefix):
+		table := &PropertyValueTable{Prefix: EastAsianWidthPrefix, Default: int(EAW_N)}
+		for eaw := range EachEastAsianWidth {
+			table.Names = append(table.Names, eaw.String())
+			table.Sets = append(table.Sets, e.EawMap[eaw])
+		}
+		return table, nil
+	case IsScriptPrefix(prefix):
+		def := e.DefRecord.ScriptDef
+		table := &PropertyValueTable{Prefix: ScriptPrefix, Default: int(def.Unknown())}
+		for sc := range def.EachScript {
+			table.Names = append(table.Names, def.GetAbbr(sc))
+			table.Sets = append(table.Sets, e.ScriptMap[sc])
+		}
+		return table, nil
+	case IsGraphemeBreakPropertyPrefix(prefix):
+		return newPropertyValueTable(GraphemeBreakPropPrefix,
+			e.DefRecord.GraphemeBreakPropDef, e.GraphemeBreakPropMap), nil
+	case IsWordBreakPropertyPrefix(prefix):
+		return newPropertyValueTable(WordBreakPropPrefix,
+			e.DefRecord.WordBreakPropDef, e.WordBreakPropMap), nil
+	case IsSentenceBreakPropertyPrefix(prefix):
+		return newPropertyValueTable(SentenceBreakPropPrefix,
+			e.DefRecord.SentenceBreakPropDef, e.SentenceBreakPropMap), nil
+	}
+	return nil, fmt.Errorf("unsupported enumerated property prefix: %s, "+
+		"must be `gc`, `ea`, `sc`, `gbp`, `wbp` or `sbp`", prefix)
+}
+
+// Values returns property value ids of all code points (index is code point)
+func (t *PropertyValueTable) Values() []int {
+	values := make([]int, utf8.MaxRune+1)
+	if t.Default != 0 {
+		for i := range values {
+			values[i] = t.Default
+		}
+	}
+	for id, uniSet := range t.Sets {
+		if uniSet == nil || id == t.Default {
+			continue
+		}
+		for runeRange := range uniSet.Range {
+			for r := runeRange.First; r <= runeRange.Last; r++ {
+				values[r] = id
+			}
+		}
+	}
+	return values
+}
+
+// LookupValue returns property value id corresponding to name.
+// if strict is true, disable loose matching (UAX44-LM3)
+func (t *PropertyValueTable) LookupValue(name string, strict bool) (int, error) {
+	if id := slices.Index(t.Names, name); id > -1 {
+		return id, nil
+	}
+	if !strict {
+		if id := slices.IndexFunc(t.Names, func(s string) bool { // loose matching
+			return looseMatchKey(s) == looseMatchKey(name)
+		}); id > -1 {
+			return id, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown property value: %s, must be one of %s", name, strings.Join(t.Names, ", "))
+}
+
+// Ranges returns sorted value-tagged ranges covering all code points.
+// if defaultValue is not negative, unlisted code points (including values of @missing lines) are tagged with defaultValue.
+// otherwise, keep values of @missing lines and tag remaining code points with Default.
+// if merge is true, adjacent ranges that have the same value are merged into one range
+func (t *PropertyValueTable) Ranges(defaultValue int, merge bool) []ValueRange {
+	override := set.UniSet{} // code points tagged with defaultValue instead of values of @missing lines
+	if defaultValue < 0 {
+		defaultValue = t.Default
+	} else if t.Unlisted != nil {
+		override = t.Unlisted.Copy()
+	}
+	var ranges []ValueRange
+	listed := set.UniSetBuilder{}
+	for id, uniSet := range t.Sets {
+		if uniSet == nil {
+			continue
+		}
+		values := uniSet.Copy()
+		values.RemoveSet(&override)
+		for runeRange := range values.Range {
+			ranges = append(ranges, ValueRange{RuneRange: runeRange, Value: id})
+		}
+		listed.AddSet(&values)
+	}
+	listedSet := listed.Build()
+	unlisted := listedSet.Complement()
+	for runeRange := range unlisted.Range {
+		ranges = append(ranges, ValueRange{RuneRange: runeRange, Value: defaultValue})
+	}
+	slices.SortFunc(ranges, func(a, b ValueRange) int {
+		return cmp.Compare(a.First, b.First)
+	})
+	if !merge {
+		return ranges
+	}
+	merged := ranges[:0]
+	for _, r := range ranges {
+		if n := len(merged); n > 0 && merged[n-1].Value == r.Value && merged[n-1].Last+1 == r.First {
+			merged[n-1].Last = r.Last
+		} else {
+			merged = append(merged, r)
+		}
+	}
+	return merged
+}
@@ -0,0 +1,106 @@
+package op
+
+import (
+	"testing"
+	"unicode/utf8"
+
+	"github.com/sekiguchi-nagisa/guniset/set"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestValueTable() *PropertyValueTable {
+	cr := set.NewUniSet('\r')
+	control := set.UniSet{}
+	control.AddRange(set.RuneRange{First: 0, Last: 9})
+	control.AddRange(set.RuneRange{First: 0xE, Last: 0x1F})
+	other := set.NewUniSet(0xB, 0xC)
+	return &PropertyValueTable{
+		Prefix:  GraphemeBreakPropPrefix,
+		Names:   []string{"CR", "Control", "Other"},
+		Sets:    []*set.UniSet{&cr, &control, &other},
+		Default: 2,
+	}
+}
+
+func TestPropertyValueTableRanges(t *testing.T) {
+	table := newTestValueTable()
+
+	ranges := table.Ranges(table.Default, false)
+	assert.Equal(t, []ValueRange{
+		{set.RuneRange{First: 0, Last: 9}, 1},
+		{set.RuneRange{First: 0xA, Last: 0xA}, 2},
+		{set.RuneRange{First: 0xB, Last: 0xC}, 2},
+		{set.RuneRange{First: 0xD, Last: 0xD}, 0},
+		{set.RuneRange{First: 0xE, Last: 0x1F}, 1},
+		{set.RuneRange{First: 0x20, Last: utf8.MaxRune}, 2},
+	}, ranges)
+
+	ranges = table.Ranges(table.Default, true)
+	assert.Equal(t, []ValueRange{
+		{set.RuneRange{First: 0, Last: 9}, 1},
+		{set.RuneRange{First: 0xA, Last: 0xC}, 2},
+		{set.RuneRange{First: 0xD, Last: 0xD}, 0},
+		{set.RuneRange{First: 0xE, Last: 0x1F}, 1},
+		{set.RuneRange{First: 0x20, Last: utf8.MaxRune}, 2},
+	}, ranges)
+
+	// change default value
+	ranges = table.Ranges(1, true)
+	assert.Equal(t, []ValueRange{
+		{set.RuneRange{First: 0, Last: 0xA}, 1},
+		{set.RuneRange{First: 0xB, Last: 0xC}, 2},
+		{set.RuneRange{First: 0xD, Last: 0xD}, 0},
+		{set.RuneRange{First: 0xE, Last: utf8.MaxRune}, 1},
+	}, ranges)
+
+	// negative default value keeps values of @missing lines
+	missing := set.UniSet{}
+	missing.AddRange(set.RuneRange{First: 0x20, Last: utf8.MaxRune})
+	table.Sets[2].AddSet(&missing)
+	table.Unlisted = &missing
+	ranges = table.Ranges(-1, true)
+	assert.Equal(t, []ValueRange{
+		{set.RuneRange{First: 0, Last: 9}, 1},
+		{set.RuneRange{First: 0xA, Last: 0xC}, 2},
+		{set.RuneRange{First: 0xD, Last: 0xD}, 0},
+		{set.RuneRange{First: 0xE, Last: 0x1F}, 1},
+		{set.RuneRange{First: 0x20, Last: utf8.MaxRune}, 2},
+	}, ranges)
+
+	// values of @missing lines are overridden by default value
+	ranges = table.Ranges(1, true)
+	assert.Equal(t, []ValueRange{
+		{set.RuneRange{First: 0, Last: 0xA}, 1},
+		{set.RuneRange{First: 0xB, Last: 0xC}, 2},
+		{set.RuneRange{First: 0xD, Last: 0xD}, 0},
+		{set.RuneRange{First: 0xE, Last: utf8.MaxRune}, 1},
+	}, ranges)
+
+	id, err := table.LookupValue("Control", false)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, id)
+	id, err = table.LookupValue("control", false)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, id)
+	_, err = table.LookupValue("LF", false)
+	assert.NotNil(t, err)
+
+	// strict
+	id, err = table.LookupValue("Control", true)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, id)
+	_, err = table.LookupValue("control", true)
+	assert.NotNil(t, err)
+}
+
+func TestPropertyValueTableValues(t *testing.T) {
+	table := newTestValueTable()
+	values := table.Values()
+	assert.Equal(t, int(utf8.MaxRune+1), len(values))
+	assert.Equal(t, 1, values[0])
+	assert.Equal(t, 2, values[0xA])
+	assert.Equal(t, 0, values['\r'])
+	assert.Equal(t, 1, values[0x1F])
+	assert.Equal(t, 2, values[0x20])
+	assert.Equal(t, 2, values[utf8.MaxRune])
+}
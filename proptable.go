@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/sekiguchi-nagisa/guniset/op"
+)
+
+// PrintPropertyTable print sorted `{ first, last, value }` table as C code
+func PrintPropertyTable(writer io.Writer, name string, valueTable *op.PropertyValueTable,
+	defaultValue int, ranges []op.ValueRange) error {
+	_, err := fmt.Fprintf(writer, "// %s property table (default: %s)\n",
+		valueTable.Prefix, valueTable.Names[defaultValue])
+	if err != nil {
+		return err
+	}
+	if err = printCEnum(writer, name, valueTable); err != nil {
+		return err
+	}
+	sb := strings.Builder{}
+	sb.WriteString(fmt.Sprintf("static const struct { uint32_t first; uint32_t last; enum %s_value value; } %s_table[] = {\n",
+		name, name))
+	for _, r := range ranges {
+		sb.WriteString(fmt.Sprintf("    { 0x%04X, 0x%04X, %s },\n",
+			r.First, r.Last, enumValueName(name, valueTable.Names[r.Value])))
+	}
+	sb.WriteString("};\n")
+	_, err = io.WriteString(writer, sb.String())
+	return err
+}
+
+func (g *GUniSet) RunAndPrintPropertyTable(name string, defaultValueName string, merge bool) error {
+	ctx, err := g.prepare()
+	if err != nil {
+		return err
+	}
+	valueTable, err := ctx.NewPropertyValueTable(g.SetOperation)
+	if err != nil {
+		return err
+	}
+	defaultValue := -1 // keep values of @missing lines
+	if defaultValueName != "" {
+		defaultValue, err = valueTable.LookupValue(defaultValueName, g.Strict)
+		if err != nil {
+			return err
+		}
+	}
+	if name == "" {
+		name = valueTable.Prefix
+	}
+	ranges := valueTable.Ranges(defaultValue, merge)
+	if defaultValue < 0 {
+		defaultValue = valueTable.Default
+	}
+	return PrintPropertyTable(g.Writer, toIdentifier(name), valueTable, defaultValue, ranges)
+}
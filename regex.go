@@ -0,0 +1,230 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/sekiguchi-nagisa/guniset/set"
+)
+
+type RegexFlavor int8
+
+const (
+	RegexNone RegexFlavor = iota
+	RegexPCRE
+	RegexECMAScript  // without `u` flag (UTF-16 code unit based)
+	RegexECMAScriptU // with `u` flag
+	RegexRE2
+	RegexDotNet
+	RegexPOSIX
+)
+
+var strToRegexFlavor = map[string]RegexFlavor{
+	"none":         RegexNone,
+	"pcre":         RegexPCRE,
+	"ecmascript":   RegexECMAScript,
+	"ecmascript-u": RegexECMAScriptU,
+	"re2":          RegexRE2,
+	"dotnet":       RegexDotNet,
+	"posix":        RegexPOSIX,
+}
+
+// isUtf16Based if true, non-bmp code points are represented as surrogate pairs
+func (f RegexFlavor) isUtf16Based() bool {
+	return f == RegexECMAScript || f == RegexDotNet
+}
+
+func (f RegexFlavor) escapeRune(r rune) string {
+	if r >= 0x21 && r <= 0x7E {
+		switch r {
+		case '\\', ']', '[', '^', '-':
+			return "\\" + string(r)
+		default:
+			return string(r)
+		}
+	}
+	switch f {
+	case RegexPCRE, RegexRE2:
+		return fmt.Sprintf("\\x{%04X}", r)
+	case RegexECMAScriptU:
+		return fmt.Sprintf("\\u{%04X}", r)
+	default: // RegexECMAScript, RegexDotNet (always bmp)
+		return fmt.Sprintf("\\u%04X", r)
+	}
+}
+
+func (f RegexFlavor) formatRange(sb *strings.Builder, runeRange set.RuneRange) {
+	sb.WriteString(f.escapeRune(runeRange.First))
+	switch runeRange.Last - runeRange.First {
+	case 0:
+	case 1:
+		sb.WriteString(f.escapeRune(runeRange.Last))
+	default:
+		sb.WriteRune('-')
+		sb.WriteString(f.escapeRune(runeRange.Last))
+	}
+}
+
+func (f RegexFlavor) formatClass(ranges []set.RuneRange) string {
+	sb := strings.Builder{}
+	sb.WriteRune('[')
+	for _, runeRange := range ranges {
+		f.formatRange(&sb, runeRange)
+	}
+	sb.WriteRune(']')
+	return sb.String()
+}
+
+func toSurrogatePair(r rune) (rune, rune) {
+	r -= 0x10000
+	return 0xD800 + (r>>10)&0x3FF, 0xDC00 + r&0x3FF
+}
+
+// formatSurrogateRange convert non-bmp range into alternation of surrogate pairs
+func (f RegexFlavor) formatSurrogateRange(runeRange set.RuneRange) []string {
+	highFirst, lowFirst := toSurrogatePair(runeRange.First)
+	highLast, lowLast := toSurrogatePair(runeRange.Last)
+	unit := func(runeRange set.RuneRange) string { // surrogate code unit does not require bracket
+		if runeRange.First == runeRange.Last {
+			return f.escapeRune(runeRange.First)
+		}
+		return f.formatClass([]set.RuneRange{runeRange})
+	}
+	pair := func(high set.RuneRange, low set.RuneRange) string {
+		return unit(high) + unit(low)
+	}
+	if highFirst == highLast {
+		return []string{pair(set.RuneRange{First: highFirst, Last: highFirst},
+			set.RuneRange{First: lowFirst, Last: lowLast})}
+	}
+	var alternatives []string
+	if lowFirst != 0xDC00 {
+		alternatives = append(alternatives, pair(set.RuneRange{First: highFirst, Last: highFirst},
+			set.RuneRange{First: lowFirst, Last: 0xDFFF}))
+		highFirst++
+	}
+	var tail []string
+	if lowLast != 0xDFFF {
+		tail = append(tail, pair(set.RuneRange{First: highLast, Last: highLast},
+			set.RuneRange{First: 0xDC00, Last: lowLast}))
+		highLast--
+	}
+	if highFirst <= highLast {
+		alternatives = append(alternatives, pair(set.RuneRange{First: highFirst, Last: highLast},
+			set.RuneRange{First: 0xDC00, Last: 0xDFFF}))
+	}
+	return append(alternatives, tail...)
+}
+
+func hasSurrogate(uniSet *set.UniSet) bool {
+	for runeRange := range uniSet.Range {
+		if runeRange.First <= 0xDFFF && runeRange.Last >= 0xD800 {
+			return true
+		}
+	}
+	return false
+}
+
+func (f RegexFlavor) formatPOSIX(uniSet *set.UniSet) (string, error) {
+	// in POSIX bracket expression, backslash is not escape character.
+	// so, place `]` at first, `-` at last and `^` at non-first position.
+	// other characters (including control characters) are written as is, except for NUL (terminates C string)
+	if uniSet.Find(0) {
+		return "", errors.New("cannot represent U+0000 in POSIX bracket expression")
+	}
+	if hasSurrogate(uniSet) { // cannot be encoded in UTF-8
+		return "", errors.New("cannot represent surrogate code points (U+D800..U+DFFF) in POSIX bracket expression")
+	}
+	tmp := uniSet.Copy()
+	hasRBracket := tmp.Remove(']')
+	hasHyphen := tmp.Remove('-')
+	hasCaret := tmp.Remove('^')
+	body := strings.Builder{}
+	for runeRange := range tmp.Range {
+		body.WriteRune(runeRange.First)
+		if runeRange.First != runeRange.Last {
+			if runeRange.Last-runeRange.First > 1 {
+				body.WriteRune('-')
+			}
+			body.WriteRune(runeRange.Last)
+		}
+	}
+
+	sb := strings.Builder{}
+	sb.WriteRune('[')
+	if hasRBracket {
+		sb.WriteRune(']')
+	}
+	sb.WriteString(body.String())
+	if hasCaret {
+		switch {
+		case sb.Len() > 1:
+			sb.WriteRune('^')
+		case hasHyphen:
+			sb.WriteString("-^")
+			hasHyphen = false
+		default:
+			sb.WriteString("[=^=]")
+		}
+	}
+	if hasHyphen {
+		sb.WriteRune('-')
+	}
+	if sb.Len() == 1 {
+		return "", errors.New("cannot represent empty set as POSIX bracket expression")
+	}
+	sb.WriteRune(']')
+	return sb.String(), nil
+}
+
+// FormatRegexClass format Unicode set as regex character class
+func FormatRegexClass(uniSet *set.UniSet, flavor RegexFlavor) (string, error) {
+	if flavor == RegexPOSIX {
+		return flavor.formatPOSIX(uniSet)
+	}
+	if flavor == RegexPCRE && hasSurrogate(uniSet) { // PCRE2 rejects surrogate code points in UTF mode
+		return "", errors.New("cannot represent surrogate code points (U+D800..U+DFFF) in PCRE")
+	}
+	if uniSet.Len() == 0 { // never match
+		if flavor.isUtf16Based() {
+			return "[^\\u0000-\\uFFFF]", nil
+		}
+		return "[^" + flavor.escapeRune(0) + "-" + flavor.escapeRune(utf8.MaxRune) + "]", nil
+	}
+	if !flavor.isUtf16Based() {
+		return flavor.formatClass(slices.Collect(uniSet.Range)), nil
+	}
+
+	bmpSet := uniSet.Copy()
+	bmpSet.RemoveRange(set.RuneRange{First: 0x10000, Last: utf8.MaxRune})
+	nonBmpSet := uniSet.Copy()
+	nonBmpSet.RemoveRange(set.RuneRange{First: 0, Last: 0xFFFF})
+	// surrogate pairs must be tried before bmp class, since bmp class may contain lead surrogates
+	var alternatives []string
+	for runeRange := range nonBmpSet.Range {
+		alternatives = append(alternatives, flavor.formatSurrogateRange(runeRange)...)
+	}
+	if bmpSet.Len() > 0 {
+		alternatives = append(alternatives, flavor.formatClass(slices.Collect(bmpSet.Range)))
+	}
+	if len(alternatives) == 1 {
+		return alternatives[0], nil
+	}
+	return "(?:" + strings.Join(alternatives, "|") + ")", nil
+}
+
+func (g *GUniSet) RunAndPrintRegex(filterOp SetFilterOp, flavor RegexFlavor) error {
+	uniSet, err := g.Run(filterOp)
+	if err != nil {
+		return err
+	}
+	regex, err := FormatRegexClass(uniSet, flavor)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(g.Writer, regex)
+	return err
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/sekiguchi-nagisa/guniset/set"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatPOSIX(t *testing.T) {
+	uniSet := set.NewUniSet('\t', '\n', 0x7F, 'A')
+	uniSet.AddRange(set.RuneRange{First: 0x01, Last: 0x03})
+	regex, err := FormatRegexClass(&uniSet, RegexPOSIX)
+	assert.Nil(t, err)
+	assert.Equal(t, "[\x01-\x03\t\nA\x7F]", regex)
+
+	// NUL terminates pattern of regcomp
+	uniSet.Add(0)
+	_, err = FormatRegexClass(&uniSet, RegexPOSIX)
+	assert.Equal(t, "cannot represent U+0000 in POSIX bracket expression", err.Error())
+}
+
+func TestFormatSurrogate(t *testing.T) {
+	uniSet := set.NewUniSet('a', 0xD83D, 0x1F600)
+	for _, flavor := range []RegexFlavor{RegexECMAScript, RegexDotNet} { // surrogate pairs are tried first
+		regex, err := FormatRegexClass(&uniSet, flavor)
+		assert.Nil(t, err)
+		assert.Equal(t, `(?:\uD83D\uDE00|[a\uD83D])`, regex)
+	}
+	regex, err := FormatRegexClass(&uniSet, RegexECMAScriptU)
+	assert.Nil(t, err)
+	assert.Equal(t, `[a\u{D83D}\u{1F600}]`, regex)
+
+	_, err = FormatRegexClass(&uniSet, RegexPCRE)
+	assert.Equal(t, "cannot represent surrogate code points (U+D800..U+DFFF) in PCRE", err.Error())
+	_, err = FormatRegexClass(&uniSet, RegexPOSIX)
+	assert.Equal(t, "cannot represent surrogate code points (U+D800..U+DFFF) in POSIX bracket expression", err.Error())
+}
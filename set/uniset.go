@@ -1,7 +1,9 @@
 package set
 
 import (
+	"cmp"
 	"fmt"
+	"maps"
 	"math/rand/v2"
 	"slices"
 	"strconv"
@@ -41,31 +43,35 @@ func ParseRune(s string) (rune, error) {
 }
 
 // UniSet set structure for Unicode code point
+//
+// code points are stored as an inversion list (sorted, non-overlapping and non-adjacent ranges)
 type UniSet struct {
-	runes []rune
+	ranges []RuneRange
 }
 
 func NewUniSet(runes ...rune) UniSet {
-	set := UniSet{}
+	builder := UniSetBuilder{}
 	for _, r := range runes {
-		set.Add(r)
+		if IsValidRune(r) {
+			builder.Add(r)
+		}
 	}
-	return set
+	return builder.Build()
 }
 
 type UniSetBuilder struct {
-	runes []rune
+	ranges []RuneRange
 }
 
 func TakeFromSet(set *UniSet) UniSetBuilder {
 	builder := UniSetBuilder{}
-	builder.runes = set.runes
-	set.runes = nil
+	builder.ranges = set.ranges
+	set.ranges = nil
 	return builder
 }
 
 func (u *UniSetBuilder) Add(r rune) {
-	u.runes = append(u.runes, r)
+	u.ranges = append(u.ranges, RuneRange{r, r})
 }
 
 func (u *UniSetBuilder) AddRange(runeRange RuneRange) {
@@ -73,150 +79,232 @@ func (u *UniSetBuilder) AddRange(runeRange RuneRange) {
 	first = max(0, first)
 	last := max(runeRange.First, runeRange.Last)
 	last = min(last, utf8.MaxRune)
-	for i := first; i <= last; i++ {
-		u.Add(i)
+	if first > last {
+		return
 	}
+	u.ranges = append(u.ranges, RuneRange{first, last})
 }
 
 func (u *UniSetBuilder) AddSet(set *UniSet) {
-	u.runes = append(u.runes, set.runes...)
+	u.ranges = append(u.ranges, set.ranges...)
 }
 
-func (u *UniSetBuilder) BuildRaw() []rune {
-	slices.Sort(u.runes)
-	u.runes = slices.Compact(u.runes)
-	var tmp = u.runes
-	u.runes = nil
-	return tmp
+// BuildRaw sort and merge added ranges
+func (u *UniSetBuilder) BuildRaw() []RuneRange {
+	slices.SortFunc(u.ranges, func(a, b RuneRange) int {
+		return cmp.Compare(a.First, b.First)
+	})
+	merged := u.ranges[:0]
+	for _, runeRange := range u.ranges {
+		if n := len(merged); n > 0 && runeRange.First <= merged[n-1].Last+1 {
+			merged[n-1].Last = max(merged[n-1].Last, runeRange.Last)
+		} else {
+			merged = append(merged, runeRange)
+		}
+	}
+	u.ranges = nil
+	return merged
 }
 
 func (u *UniSetBuilder) Build() UniSet {
-	return UniSet{runes: u.BuildRaw()}
+	return UniSet{ranges: u.BuildRaw()}
 }
 
 func NewUniSetAll() UniSet {
-	builder := UniSetBuilder{}
-	builder.AddRange(RuneRange{0, utf8.MaxRune})
-	return builder.Build()
+	return UniSet{ranges: []RuneRange{{0, utf8.MaxRune}}}
+}
+
+// search returns the index of the first range whose Last is not less than r
+func (u *UniSet) search(r rune) int {
+	i, _ := slices.BinarySearchFunc(u.ranges, r, func(runeRange RuneRange, r rune) int {
+		if runeRange.Last < r {
+			return -1
+		}
+		if runeRange.First > r {
+			return 1
+		}
+		return 0
+	})
+	return i
 }
 
 func (u *UniSet) Add(r rune) bool {
-	if !IsValidRune(r) {
-		return false
-	}
-	if u.runes == nil {
-		u.runes = []rune{}
-	}
-	if len(u.runes) == 0 {
-		u.runes = append(u.runes, r)
-		return true
-	}
-	i, s := slices.BinarySearch(u.runes, r)
-	if s {
+	if !IsValidRune(r) || u.Find(r) {
 		return false
 	}
-	u.runes = slices.Insert(u.runes, i, r)
+	u.AddRange(RuneRange{r, r})
 	return true
 }
 
 func (u *UniSet) AddRange(runeRange RuneRange) {
-	builder := TakeFromSet(u)
+	other := UniSet{}
+	builder := UniSetBuilder{}
 	builder.AddRange(runeRange)
-	u.runes = builder.BuildRaw()
+	other.ranges = builder.BuildRaw()
+	u.AddSet(&other)
 }
 
+// AddSet union (linear merge)
 func (u *UniSet) AddSet(other *UniSet) {
-	if other == nil || u == other {
+	if other == nil || u == other || len(other.ranges) == 0 {
+		return
+	}
+	if len(u.ranges) == 0 {
+		u.ranges = slices.Clone(other.ranges)
 		return
 	}
-	builder := TakeFromSet(u)
-	builder.AddSet(other)
-	u.runes = builder.BuildRaw()
+	merged := make([]RuneRange, 0, len(u.ranges)+len(other.ranges))
+	appendRange := func(runeRange RuneRange) {
+		if n := len(merged); n > 0 && runeRange.First <= merged[n-1].Last+1 {
+			merged[n-1].Last = max(merged[n-1].Last, runeRange.Last)
+		} else {
+			merged = append(merged, runeRange)
+		}
+	}
+	i, j := 0, 0
+	for i < len(u.ranges) && j < len(other.ranges) {
+		if u.ranges[i].First <= other.ranges[j].First {
+			appendRange(u.ranges[i])
+			i++
+		} else {
+			appendRange(other.ranges[j])
+			j++
+		}
+	}
+	for ; i < len(u.ranges); i++ {
+		appendRange(u.ranges[i])
+	}
+	for ; j < len(other.ranges); j++ {
+		appendRange(other.ranges[j])
+	}
+	u.ranges = merged
 }
 
 func (u *UniSet) Remove(r rune) bool {
-	if u.runes == nil || len(u.runes) == 0 {
+	if !u.Find(r) {
 		return false
 	}
-	i, s := slices.BinarySearch(u.runes, r)
-	if s {
-		u.runes = slices.Delete(u.runes, i, i+1)
-	}
-	return s
+	u.RemoveRange(RuneRange{r, r})
+	return true
 }
 
 func (u *UniSet) RemoveRange(runeRange RuneRange) {
 	first := min(runeRange.First, runeRange.Last)
 	last := max(runeRange.First, runeRange.Last)
-	u.runes = slices.DeleteFunc(u.runes, func(r rune) bool {
-		return r >= first && r <= last
-	})
+	u.RemoveSet(&UniSet{ranges: []RuneRange{{first, last}}})
 }
 
+// RemoveSet difference (linear merge)
 func (u *UniSet) RemoveSet(other *UniSet) {
-	if other == nil || u == other {
+	if other == nil || len(other.ranges) == 0 || len(u.ranges) == 0 {
 		return
 	}
-	u.runes = slices.DeleteFunc(u.runes, func(r rune) bool {
-		return other.Find(r)
-	})
+	if u == other {
+		u.ranges = nil
+		return
+	}
+	var ranges []RuneRange
+	j := 0
+	for _, cur := range u.ranges {
+		for j < len(other.ranges) && other.ranges[j].Last < cur.First {
+			j++
+		}
+		for k := j; k < len(other.ranges) && other.ranges[k].First <= cur.Last; k++ {
+			removed := other.ranges[k]
+			if removed.First > cur.First {
+				ranges = append(ranges, RuneRange{cur.First, removed.First - 1})
+			}
+			cur.First = removed.Last + 1
+			if cur.First > cur.Last {
+				break
+			}
+		}
+		if cur.First <= cur.Last {
+			ranges = append(ranges, cur)
+		}
+	}
+	u.ranges = ranges
 }
 
+// AndSet intersection (linear merge)
 func (u *UniSet) AndSet(other *UniSet) UniSet {
-	builder := UniSetBuilder{}
-	for _, r := range other.runes {
-		if u.Find(r) {
-			builder.Add(r)
+	var ranges []RuneRange
+	i, j := 0, 0
+	for i < len(u.ranges) && j < len(other.ranges) {
+		a, b := u.ranges[i], other.ranges[j]
+		first := max(a.First, b.First)
+		last := min(a.Last, b.Last)
+		if first <= last {
+			ranges = append(ranges, RuneRange{first, last})
+		}
+		if a.Last < b.Last {
+			i++
+		} else {
+			j++
 		}
 	}
-	return builder.Build()
+	return UniSet{ranges: ranges}
+}
+
+// Complement returns the set of valid code points that are not included in this set
+func (u *UniSet) Complement() UniSet {
+	ranges := make([]RuneRange, 0, len(u.ranges)+1)
+	next := rune(0)
+	for _, runeRange := range u.ranges {
+		if next < runeRange.First {
+			ranges = append(ranges, RuneRange{next, runeRange.First - 1})
+		}
+		next = runeRange.Last + 1
+	}
+	if next <= utf8.MaxRune {
+		ranges = append(ranges, RuneRange{next, utf8.MaxRune})
+	}
+	return UniSet{ranges: ranges}
 }
 
 func (u *UniSet) Filter(f func(r rune) bool) {
-	u.runes = slices.DeleteFunc(u.runes, func(r rune) bool {
-		return !f(r)
-	})
+	builder := UniSetBuilder{}
+	for r := range u.Iter {
+		if f(r) {
+			builder.Add(r)
+		}
+	}
+	u.ranges = builder.BuildRaw()
 }
 
 func (u *UniSet) Find(r rune) bool {
-	_, s := slices.BinarySearch(u.runes, r)
-	return s
+	i := u.search(r)
+	return i < len(u.ranges) && u.ranges[i].First <= r
 }
 
 func (u *UniSet) Copy() UniSet {
 	copied := UniSet{}
-	copied.runes = u.runes[0:]
+	copied.ranges = slices.Clone(u.ranges)
 	return copied
 }
 
 func (u *UniSet) Len() int {
-	return len(u.runes)
+	size := 0
+	for _, runeRange := range u.ranges {
+		size += int(runeRange.Last-runeRange.First) + 1
+	}
+	return size
 }
 
 func (u *UniSet) Range(yield func(runeRange RuneRange) bool) {
-	for i := 0; i < len(u.runes); {
-		first := u.runes[i]
-		last := first
-		i += 1
-		for i < len(u.runes) {
-			cur := u.runes[i]
-			if last+1 == cur {
-				last = cur
-				i += 1
-			} else {
-				break
-			}
-		}
-		if !yield(RuneRange{first, last}) {
+	for _, runeRange := range u.ranges {
+		if !yield(runeRange) {
 			return
 		}
 	}
 }
 
 func (u *UniSet) Iter(yield func(r rune) bool) {
-	for _, r := range u.runes {
-		if !yield(r) {
-			return
+	for _, runeRange := range u.ranges {
+		for r := runeRange.First; r <= runeRange.Last; r++ {
+			if !yield(r) {
+				return
+			}
 		}
 	}
 }
@@ -224,7 +312,7 @@ func (u *UniSet) Iter(yield func(r rune) bool) {
 func (u *UniSet) String() string {
 	sb := strings.Builder{}
 	sb.WriteRune('{')
-	sb.Grow(len(u.runes) / 4)
+	sb.Grow(len(u.ranges) * 16)
 	c := 0
 	for runeRange := range u.Range {
 		if c > 0 {
@@ -237,33 +325,53 @@ func (u *UniSet) String() string {
 	return sb.String()
 }
 
+// runeSampler maps index (0 <= index < Len()) to code point
+type runeSampler struct {
+	ranges  []RuneRange
+	offsets []int // start index of each range
+}
+
+func newRuneSampler(u *UniSet) runeSampler {
+	sampler := runeSampler{ranges: u.ranges, offsets: make([]int, len(u.ranges))}
+	offset := 0
+	for i, runeRange := range u.ranges {
+		sampler.offsets[i] = offset
+		offset += int(runeRange.Last-runeRange.First) + 1
+	}
+	return sampler
+}
+
+func (s *runeSampler) at(index int) rune {
+	i, found := slices.BinarySearch(s.offsets, index)
+	if !found {
+		i--
+	}
+	return s.ranges[i].First + rune(index-s.offsets[i])
+}
+
 func (u *UniSet) Sample(rnd *rand.Rand, limit int) UniSet {
 	if limit <= 0 {
 		return UniSet{}
 	}
-	if limit >= len(u.runes) {
+	size := u.Len()
+	if limit >= size {
 		return u.Copy()
 	}
 
+	sampler := newRuneSampler(u)
 	runeSet := map[rune]struct{}{}
-	builder := UniSetBuilder{}
-	if limit > u.Len()/2 {
-		removeCount := u.Len() - limit
+	if limit > size/2 {
+		removeCount := size - limit
 		for len(runeSet) < removeCount {
-			runeSet[u.runes[rnd.IntN(len(u.runes))]] = struct{}{}
-		}
-		for _, r := range u.runes {
-			if _, ok := runeSet[r]; !ok {
-				builder.Add(r)
-			}
-		}
-	} else {
-		for len(runeSet) < limit {
-			runeSet[u.runes[rnd.IntN(len(u.runes))]] = struct{}{}
-		}
-		for r := range runeSet {
-			builder.Add(r)
+			runeSet[sampler.at(rnd.IntN(size))] = struct{}{}
 		}
+		removed := NewUniSet(slices.Collect(maps.Keys(runeSet))...)
+		sampled := u.Copy()
+		sampled.RemoveSet(&removed)
+		return sampled
 	}
-	return builder.Build()
+	for len(runeSet) < limit {
+		runeSet[sampler.at(rnd.IntN(size))] = struct{}{}
+	}
+	return NewUniSet(slices.Collect(maps.Keys(runeSet))...)
 }
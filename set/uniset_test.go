@@ -151,3 +151,59 @@ func TestSample(t *testing.T) {
 	sampled = set.Sample(rnd, -122)
 	assert.Equal(t, 0, sampled.Len(), "negative sample size")
 }
+
+func TestRangeOperation(t *testing.T) {
+	builder := UniSetBuilder{}
+	builder.AddRange(RuneRange{'a', 'f'})
+	builder.AddRange(RuneRange{'d', 'k'})
+	builder.AddRange(RuneRange{'l', 'm'})
+	builder.AddRange(RuneRange{'x', 'z'})
+	set := builder.Build()
+	assert.Equal(t, fmt.Sprintf("{0x%04x..0x%04x,0x%04x..0x%04x}", 'a', 'm', 'x', 'z'), set.String())
+	assert.Equal(t, 16, set.Len())
+
+	// union
+	other := NewUniSet('n', 'p', 'w')
+	set.AddSet(&other)
+	assert.Equal(t, fmt.Sprintf("{0x%04x..0x%04x,0x%04x..0x%04x,0x%04x..0x%04x}",
+		'a', 'n', 'p', 'p', 'w', 'z'), set.String())
+
+	// difference
+	other = NewUniSet('a', 'c', 'n', 'p', 'y')
+	set.RemoveSet(&other)
+	assert.Equal(t, fmt.Sprintf("{0x%04x..0x%04x,0x%04x..0x%04x,0x%04x..0x%04x,0x%04x..0x%04x}",
+		'b', 'b', 'd', 'm', 'w', 'x', 'z', 'z'), set.String())
+
+	// intersection
+	builder.AddRange(RuneRange{'c', 'e'})
+	builder.AddRange(RuneRange{'l', 'y'})
+	other = builder.Build()
+	set = set.AndSet(&other)
+	assert.Equal(t, fmt.Sprintf("{0x%04x..0x%04x,0x%04x..0x%04x,0x%04x..0x%04x}",
+		'd', 'e', 'l', 'm', 'w', 'x'), set.String())
+}
+
+func TestComplement(t *testing.T) {
+	set := NewUniSet()
+	set = set.Complement()
+	assert.Equal(t, "{0x0000..0x10ffff}", set.String())
+	assert.Equal(t, 0x110000, set.Len())
+	all := NewUniSetAll()
+	assert.Equal(t, all.String(), set.String())
+
+	set = set.Complement()
+	assert.Equal(t, "{}", set.String())
+
+	set = NewUniSet(0, 5, 6, 0x10FFFF)
+	set = set.Complement()
+	assert.Equal(t, "{0x0001..0x0004,0x0007..0x10fffe}", set.String())
+	assert.False(t, set.Find(0))
+	assert.True(t, set.Find(1))
+	assert.False(t, set.Find(6))
+	assert.True(t, set.Find(0x10FFFE))
+	assert.False(t, set.Find(0x10FFFF))
+
+	// remove bmp
+	set.RemoveRange(RuneRange{0, 0xFFFF})
+	assert.Equal(t, "{0x10000..0x10fffe}", set.String())
+}
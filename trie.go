@@ -0,0 +1,207 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math/bits"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/sekiguchi-nagisa/guniset/op"
+)
+
+// MultiStageTable multi-stage lookup table (trie) for code point to property value
+//
+//	two-stage:   stage2[(stage1[cp >> s1] << s1) | (cp & (2^s1 - 1))]
+//	three-stage: stage3[(stage2[(stage1[cp >> (s1 + s2)] << s2) | ((cp >> s1) & (2^s2 - 1))] << s1) | (cp & (2^s1 - 1))]
+type MultiStageTable struct {
+	Shifts []int   // block shift of each level (from data stage)
+	Stages [][]int // stage tables (from top-level index to data stage)
+}
+
+func isPowerOfTwo(n int) bool {
+	return n > 0 && n&(n-1) == 0
+}
+
+// splitBlocks split values into deduplicated blocks
+func splitBlocks(values []int, blockSize int) (index []int, data []int) {
+	blockToIndex := map[string]int{}
+	for i := 0; i < len(values); i += blockSize {
+		block := values[i : i+blockSize]
+		key := fmt.Sprint(block)
+		blockIndex, ok := blockToIndex[key]
+		if !ok {
+			blockIndex = len(blockToIndex)
+			blockToIndex[key] = blockIndex
+			data = append(data, block...)
+		}
+		index = append(index, blockIndex)
+	}
+	return index, data
+}
+
+// NewMultiStageTable build multi-stage table.
+// blockSizes are block size of each level (from data stage). each block size must be power of two
+func NewMultiStageTable(values []int, blockSizes []int) (*MultiStageTable, error) {
+	table := &MultiStageTable{}
+	current := values
+	for _, blockSize := range blockSizes {
+		if !isPowerOfTwo(blockSize) {
+			return nil, fmt.Errorf("block size must be power of two: %d", blockSize)
+		}
+		if len(current)%blockSize != 0 {
+			return nil, fmt.Errorf("block size is too large: %d", blockSize)
+		}
+		index, data := splitBlocks(current, blockSize)
+		table.Shifts = append(table.Shifts, bits.TrailingZeros(uint(blockSize)))
+		table.Stages = append(table.Stages, data)
+		current = index
+	}
+	table.Stages = append(table.Stages, current)
+	slices.Reverse(table.Stages)
+	return table, nil
+}
+
+func (t *MultiStageTable) Lookup(r rune) int {
+	shift := 0
+	for _, s := range t.Shifts {
+		shift += s
+	}
+	value := t.Stages[0][int(r)>>shift]
+	for i := 1; i < len(t.Stages); i++ {
+		s := t.Shifts[len(t.Stages)-1-i]
+		shift -= s
+		value = t.Stages[i][value<<s|(int(r)>>shift)&(1<<s-1)]
+	}
+	return value
+}
+
+func elementSize(stage []int) int {
+	maxValue := slices.Max(stage)
+	switch {
+	case maxValue <= 0xFF:
+		return 1
+	case maxValue <= 0xFFFF:
+		return 2
+	default:
+		return 4
+	}
+}
+
+// Size returns total table size (bytes)
+func (t *MultiStageTable) Size() int {
+	size := 0
+	for _, stage := range t.Stages {
+		size += len(stage) * elementSize(stage)
+	}
+	return size
+}
+
+func toIdentifier(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			return r
+		}
+		return '_'
+	}, s)
+}
+
+func enumValueName(name string, valueName string) string {
+	return strings.ToUpper(name) + "_" + toIdentifier(valueName)
+}
+
+// printCEnum print property values as C enum declaration (`enum <name>_value`)
+func printCEnum(writer io.Writer, name string, valueTable *op.PropertyValueTable) error {
+	sb := strings.Builder{}
+	sb.WriteString(fmt.Sprintf("enum %s_value {\n", name))
+	for i, valueName := range valueTable.Names {
+		sb.WriteString(fmt.Sprintf("    %s = %d,\n", enumValueName(name, valueName), i))
+	}
+	sb.WriteString("};\n\n")
+	_, err := io.WriteString(writer, sb.String())
+	return err
+}
+
+func printCArray(writer io.Writer, name string, values []int) error {
+	sb := strings.Builder{}
+	sb.WriteString(fmt.Sprintf("static const uint%d_t %s[%d] = {", elementSize(values)*8, name, len(values)))
+	for i, v := range values {
+		if i%16 == 0 {
+			sb.WriteString("\n   ")
+		}
+		sb.WriteString(fmt.Sprintf(" %d,", v))
+	}
+	sb.WriteString("\n};\n")
+	_, err := io.WriteString(writer, sb.String())
+	return err
+}
+
+// PrintMultiStageTable print multi-stage table as C code
+func PrintMultiStageTable(writer io.Writer, name string, valueTable *op.PropertyValueTable, table *MultiStageTable) error {
+	var blockSizes []string
+	for _, s := range table.Shifts {
+		blockSizes = append(blockSizes, fmt.Sprint(1<<s))
+	}
+	_, err := fmt.Fprintf(writer, "// %s lookup table (stages: %d, block sizes: %s)\n// total size: %d bytes\n",
+		valueTable.Prefix, len(table.Stages), strings.Join(blockSizes, ", "), table.Size())
+	if err != nil {
+		return err
+	}
+
+	if err = printCEnum(writer, name, valueTable); err != nil {
+		return err
+	}
+
+	// stages
+	for i, stage := range table.Stages {
+		if err = printCArray(writer, fmt.Sprintf("%s_stage%d", name, i+1), stage); err != nil {
+			return err
+		}
+		if _, err = fmt.Fprintln(writer); err != nil {
+			return err
+		}
+	}
+
+	// lookup function
+	shift := 0
+	for _, s := range table.Shifts {
+		shift += s
+	}
+	expr := fmt.Sprintf("%s_stage1[cp >> %d]", name, shift)
+	for i := 1; i < len(table.Stages); i++ {
+		s := table.Shifts[len(table.Stages)-1-i]
+		shift -= s
+		offset := "cp"
+		if shift > 0 {
+			offset = fmt.Sprintf("(cp >> %d)", shift)
+		}
+		expr = fmt.Sprintf("%s_stage%d[(%s << %d) | (%s & 0x%X)]", name, i+1, expr, s, offset, 1<<s-1)
+	}
+	_, err = fmt.Fprintf(writer, "static inline enum %s_value %s_lookup(uint32_t cp) {\n"+
+		"    if (cp > 0x10FFFF) {\n"+
+		"        return %s;\n"+
+		"    }\n"+
+		"    return (enum %s_value)%s;\n"+
+		"}\n", name, name, enumValueName(name, valueTable.Names[valueTable.Default]), name, expr)
+	return err
+}
+
+func (g *GUniSet) RunAndPrintLookupTable(name string, blockSizes []int) error {
+	ctx, err := g.prepare()
+	if err != nil {
+		return err
+	}
+	valueTable, err := ctx.NewPropertyValueTable(g.SetOperation)
+	if err != nil {
+		return err
+	}
+	table, err := NewMultiStageTable(valueTable.Values(), blockSizes)
+	if err != nil {
+		return err
+	}
+	if name == "" {
+		name = valueTable.Prefix
+	}
+	return PrintMultiStageTable(g.Writer, toIdentifier(name), valueTable, table)
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMultiStageTable(t *testing.T) {
+	values := make([]int, utf8.MaxRune+1)
+	for i := range values {
+		switch {
+		case i < 0x80:
+			values[i] = i % 3
+		case i >= 0x3000 && i < 0x4000:
+			values[i] = 3
+		case i >= 0x20000 && i < 0x20100:
+			values[i] = 300
+		}
+	}
+
+	for _, blockSizes := range [][]int{{128}, {64, 16}, {1}, {256, 256}} {
+		table, err := NewMultiStageTable(values, blockSizes)
+		assert.Nil(t, err)
+		assert.Equal(t, len(blockSizes)+1, len(table.Stages))
+		for r := rune(0); r <= utf8.MaxRune; r++ {
+			if values[r] != table.Lookup(r) {
+				assert.Equal(t, values[r], table.Lookup(r), fmt.Sprintf("U+%04X, %v", r, blockSizes))
+				break
+			}
+		}
+	}
+
+	table, err := NewMultiStageTable(values, []int{128})
+	assert.Nil(t, err)
+	assert.Equal(t, 0x110000/128, len(table.Stages[0]))
+	assert.Equal(t, 4*128, len(table.Stages[1])) // deduplicated blocks
+	assert.Equal(t, 0x110000/128+4*128*2, table.Size())
+
+	_, err = NewMultiStageTable(values, []int{100})
+	assert.NotNil(t, err)
+	_, err = NewMultiStageTable(values, []int{0x100000})
+	assert.NotNil(t, err)
+}